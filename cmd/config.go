@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/spf13/cobra"
+
+	"github.com/sentinel-official/cli-client/context"
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func ConfigCmd() *cobra.Command {
+	var (
+		cmd = &cobra.Command{
+			Use:                        "config",
+			Short:                      "Config subcommands",
+			DisableFlagParsing:         true,
+			SuggestionsMinimumDistance: 2,
+			RunE:                       client.ValidateCmd,
+		}
+	)
+
+	cmd.AddCommand(
+		configDefaultsCmd(),
+		configExportCmd(),
+		configImportCmd(),
+		configSetDefaultCmd(),
+		configUnsetDefaultCmd(),
+	)
+
+	return cmd
+}
+
+var (
+	defaultKeys = []string{
+		clitypes.FlagDNSSearch,
+		clitypes.FlagInterface,
+		clitypes.FlagKeepalive,
+		clitypes.FlagKillSwitch,
+		clitypes.FlagMode,
+		clitypes.FlagMTU,
+		clitypes.FlagNoNodeResolver,
+		clitypes.FlagProxyListen,
+		clitypes.FlagResolver,
+		clitypes.FlagServiceBackend,
+	}
+)
+
+func isDefaultKey(key string) bool {
+	for _, v := range defaultKeys {
+		if v == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func defaultsFilePathFromCmd(cmd *cobra.Command) (string, error) {
+	home, err := cmd.Flags().GetString(clitypes.FlagServiceHome)
+	if err != nil {
+		return "", err
+	}
+
+	return clitypes.DefaultsFilePath(home), nil
+}
+
+func applyDefaultsToCmd(cmd *cobra.Command) error {
+	path, err := defaultsFilePathFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+
+	defaults, err := clitypes.LoadDefaults(path)
+	if err != nil {
+		return err
+	}
+
+	return defaults.ApplyToCmd(cmd)
+}
+
+func configDefaultsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "defaults",
+		Short: "Show the persisted defaults of the connect command",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := defaultsFilePathFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			defaults, err := clitypes.LoadDefaults(path)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range defaults.Keys() {
+				for _, value := range defaults[key] {
+					if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%s = %s\n", key, value); err != nil {
+						return err
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+
+	return cmd
+}
+
+func configSetDefaultCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-default [key] [value]...",
+		Short: "Persist a default value of a connect flag",
+		Long: fmt.Sprintf("Persist a default value of a connect flag. Supported keys are %s.",
+			strings.Join(defaultKeys, ", ")),
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !isDefaultKey(args[0]) {
+				return fmt.Errorf("unsupported key %s", args[0])
+			}
+
+			connect := ConnectCmd()
+			for _, value := range args[1:] {
+				if err := connect.Flags().Set(args[0], value); err != nil {
+					return fmt.Errorf("invalid %s %s: %w", args[0], value, err)
+				}
+			}
+
+			path, err := defaultsFilePathFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			defaults, err := clitypes.LoadDefaults(path)
+			if err != nil {
+				return err
+			}
+
+			defaults[args[0]] = args[1:]
+			return defaults.SaveToPath(path)
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+
+	return cmd
+}
+
+func configUnsetDefaultCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset-default [key]",
+		Short: "Remove a persisted default value of a connect flag",
+		Long: fmt.Sprintf("Remove a persisted default value of a connect flag. Supported keys are %s.",
+			strings.Join(defaultKeys, ", ")),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !isDefaultKey(args[0]) {
+				return fmt.Errorf("unsupported key %s", args[0])
+			}
+
+			path, err := defaultsFilePathFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			defaults, err := clitypes.LoadDefaults(path)
+			if err != nil {
+				return err
+			}
+
+			delete(defaults, args[0])
+			return defaults.SaveToPath(path)
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+
+	return cmd
+}
+
+func configExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export the wg-quick config of a connection",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := cmd.Flags().GetString(clitypes.FlagName)
+			if err != nil {
+				return err
+			}
+
+			sc, err := context.NewServiceContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := sc.ExportConfig(name)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				_, err = fmt.Fprint(cmd.OutOrStdout(), cfg)
+				return err
+			}
+
+			return os.WriteFile(args[0], []byte(cfg), 0600)
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+	clitypes.AddTimeoutFlagsToCmd(cmd)
+
+	cmd.Flags().String(clitypes.FlagName, clitypes.DefaultConnection, "name of the connection")
+
+	return cmd
+}
+
+func configImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Bring up a connection from a wg-quick config",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := cmd.Flags().GetString(clitypes.FlagName)
+			if err != nil {
+				return err
+			}
+
+			killSwitch, err := cmd.Flags().GetBool(clitypes.FlagKillSwitch)
+			if err != nil {
+				return err
+			}
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			_, warnings, err := wireguardtypes.ParseWgQuickWithWarnings(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+
+			for _, warning := range warnings {
+				cmd.PrintErrf("%s: %s\n", args[0], warning)
+			}
+
+			sc, err := context.NewServiceContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			return sc.ImportConfig(
+				&restrequests.ImportConfig{
+					Name:       name,
+					Config:     string(data),
+					KillSwitch: killSwitch,
+				},
+			)
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+	clitypes.AddTimeoutFlagsToCmd(cmd)
+
+	cmd.Flags().Bool(clitypes.FlagKillSwitch, false, "block the traffic outside the tunnel until disconnect")
+	cmd.Flags().String(clitypes.FlagName, clitypes.DefaultConnection, "name of the connection")
+
+	return cmd
+}
@@ -2,23 +2,21 @@ package cmd
 
 import (
 	"bufio"
-	"bytes"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"net"
 	"strconv"
-	"strings"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/go-kit/kit/transport/http/jsonrpc"
+	"github.com/pkg/errors"
 	hubtypes "github.com/sentinel-official/hub/types"
-	sessiontypes "github.com/sentinel-official/hub/x/session/types"
+	nodetypes "github.com/sentinel-official/hub/x/node/types"
 	"github.com/spf13/cobra"
 
 	"github.com/sentinel-official/cli-client/context"
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
 	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
 	clitypes "github.com/sentinel-official/cli-client/types"
+	clinodetypes "github.com/sentinel-official/cli-client/x/node/types"
 )
 
 func parseResolversFromCmd(cmd *cobra.Command) ([]net.IP, error) {
@@ -40,180 +38,393 @@ func parseResolversFromCmd(cmd *cobra.Command) ([]net.IP, error) {
 	return items, nil
 }
 
+func parseIPNetsFromCmd(cmd *cobra.Command, name string) ([]string, error) {
+	v, err := cmd.Flags().GetStringArray(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range v {
+		if _, err := wireguardtypes.ParseIPNet(s); err != nil {
+			return nil, fmt.Errorf("invalid %s cidr %s", name, s)
+		}
+	}
+
+	return v, nil
+}
+
+const (
+	autoConnectAttempts = 3
+)
+
+func queryAutoNodes(cmd *cobra.Command, tc *context.TxContext, id uint64, mode string) ([]clinodetypes.Node, error) {
+	country, err := cmd.Flags().GetString(clitypes.FlagCountry)
+	if err != nil {
+		return nil, err
+	}
+
+	provAddr, err := clitypes.GetProvAddressFromCmd(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := cmd.Flags().GetUint64(clitypes.FlagPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := cmd.Flags().GetString(clitypes.FlagMaxPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	var maxPrice sdk.Coin
+	if s != "" {
+		maxPrice, err = sdk.ParseCoinNormalized(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	subscription, err := tc.QuerySubscription(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var items nodetypes.Nodes
+	if subscription.Node != "" {
+		nodeAddr, err := hubtypes.NodeAddressFromBech32(subscription.Node)
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := tc.QueryNode(nodeAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, *node)
+	} else {
+		items, err = tc.QueryAllNodesForPlan(subscription.Plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if plan != 0 && plan != subscription.Plan {
+		result, err := tc.QueryAllNodesForPlan(plan)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed := make(map[string]bool)
+		for _, item := range result {
+			allowed[item.Address] = true
+		}
+
+		var v nodetypes.Nodes
+		for _, item := range items {
+			if allowed[item.Address] {
+				v = append(v, item)
+			}
+		}
+
+		items = v
+	}
+
+	var (
+		filter = clinodetypes.Filter{
+			Country:  country,
+			MaxPrice: maxPrice,
+			Types:    []uint64{clitypes.ServiceTypeWireGuard, clitypes.ServiceTypeV2Ray},
+		}
+		active = make(nodetypes.Nodes, 0, len(items))
+		nodes  = make([]clinodetypes.Node, 0, len(items))
+	)
+
+	if provAddr != nil {
+		filter.Provider = provAddr.String()
+	}
+	if mode == clitypes.ServiceModeProxy {
+		filter.Types = []uint64{clitypes.ServiceTypeWireGuard}
+	}
+
+	for i := 0; i < len(items); i++ {
+		if items[i].Status.Equal(hubtypes.StatusActive) {
+			active = append(active, items[i])
+		}
+	}
+
+	result, errs := clinodetypes.FetchNodesWithInfo(active, tc.Timeout)
+	for i := 0; i < len(result); i++ {
+		if errs[i] == nil && filter.Match(result[i]) {
+			nodes = append(nodes, result[i])
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, errors.New("no reachable node matches the filters")
+	}
+
+	denom := maxPrice.Denom
+	if denom == "" {
+		denom = subscription.Deposit.Denom
+	}
+
+	clinodetypes.RankNodes(nodes, denom)
+	return nodes, nil
+}
+
+func newTxRequest(tc *context.TxContext) *restrequests.Tx {
+	return &restrequests.Tx{
+		BroadcastMode: tc.BroadcastMode,
+		ChainID:       tc.ChainID,
+		Gas:           tc.Gas,
+		GasPrices:     tc.GasPrices.String(),
+		Memo:          tc.Memo,
+		RPCAddress:    tc.NodeURI,
+	}
+}
+
 func ConnectCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "connect [subscription] [address]",
 		Short: "Connect to a node",
-		Args:  cobra.ExactArgs(2),
+		Args:  cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id, err := strconv.ParseUint(args[0], 10, 64)
-			if err != nil {
+			if err := applyDefaultsToCmd(cmd); err != nil {
 				return err
 			}
 
-			nodeAddr, err := hubtypes.NodeAddressFromBech32(args[1])
+			id, err := strconv.ParseUint(args[0], 10, 64)
 			if err != nil {
 				return err
 			}
 
-			rating, err := cmd.Flags().GetUint64(clitypes.FlagRating)
+			auto, err := cmd.Flags().GetBool(clitypes.FlagAuto)
 			if err != nil {
 				return err
 			}
+			if auto != (len(args) == 1) {
+				return errors.New("either the node address or the flag --auto must be provided")
+			}
 
-			resolvers, err := parseResolversFromCmd(cmd)
+			rating, err := cmd.Flags().GetUint64(clitypes.FlagRating)
 			if err != nil {
 				return err
 			}
 
-			tc, err := context.NewTxContextFromCmd(cmd)
+			name, err := cmd.Flags().GetString(clitypes.FlagName)
 			if err != nil {
 				return err
 			}
 
-			sc, err := context.NewServiceContextFromCmd(cmd)
+			killSwitch, err := cmd.Flags().GetBool(clitypes.FlagKillSwitch)
 			if err != nil {
 				return err
 			}
 
-			status, err := sc.GetStatus()
+			s, err := cmd.Flags().GetString(clitypes.FlagMaxBytes)
 			if err != nil {
 				return err
 			}
 
-			if status.IFace != "" {
-				if err := sc.Disconnect(); err != nil {
+			var maxBytes int64
+			if s != "" {
+				maxBytes, err = clitypes.ParseBytes(s)
+				if err != nil {
 					return err
 				}
 			}
 
-			var (
-				messages []sdk.Msg
-				reader   = bufio.NewReader(cmd.InOrStdin())
-			)
-
-			password, from, err := tc.GetPasswordAndAddress(reader, tc.From)
+			maxDuration, err := cmd.Flags().GetDuration(clitypes.FlagMaxDuration)
 			if err != nil {
 				return err
 			}
 
-			session, err := tc.QueryActiveSession(from)
+			endSession, err := cmd.Flags().GetBool(clitypes.FlagEndSession)
 			if err != nil {
 				return err
 			}
 
-			if session != nil {
-				messages = append(
-					messages,
-					sessiontypes.NewMsgEndRequest(
-						from,
-						session.Id,
-						rating,
-					),
-				)
+			iFace, err := cmd.Flags().GetString(clitypes.FlagInterface)
+			if err != nil {
+				return err
 			}
 
-			messages = append(
-				messages,
-				sessiontypes.NewMsgStartRequest(
-					from,
-					id,
-					nodeAddr,
-				),
-			)
+			mtu, err := cmd.Flags().GetUint16(clitypes.FlagMTU)
+			if err != nil {
+				return err
+			}
 
-			txRes, err := tc.SignMessagesAndBroadcastTx(password, messages...)
+			keepalive, err := cmd.Flags().GetUint16(clitypes.FlagKeepalive)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println(txRes)
+			dnsSearch, err := cmd.Flags().GetStringArray(clitypes.FlagDNSSearch)
+			if err != nil {
+				return err
+			}
 
-			session, err = tc.QueryActiveSession(from)
+			mode, err := cmd.Flags().GetString(clitypes.FlagMode)
 			if err != nil {
 				return err
 			}
-			if session == nil {
-				return fmt.Errorf("active session does not exist for subscription %d", id)
+			if mode != clitypes.ServiceModeTunnel && mode != clitypes.ServiceModeProxy {
+				return fmt.Errorf("mode must be either %s or %s", clitypes.ServiceModeTunnel, clitypes.ServiceModeProxy)
 			}
 
-			node, err := tc.QueryNode(nodeAddr)
+			proxyListen, err := cmd.Flags().GetString(clitypes.FlagProxyListen)
 			if err != nil {
 				return err
 			}
+			if mode != clitypes.ServiceModeProxy {
+				proxyListen = ""
+			}
 
-			wgPrivateKey, err := wireguardtypes.NewPrivateKey()
+			serviceBackend, err := cmd.Flags().GetString(clitypes.FlagServiceBackend)
 			if err != nil {
 				return err
 			}
 
-			signMsgRes, err := tc.SignMessage(
-				password,
-				tc.From,
-				sdk.Uint64ToBigEndian(session.Id),
-			)
+			resolvers, err := parseResolversFromCmd(cmd)
 			if err != nil {
 				return err
 			}
 
-			signature, err := base64.StdEncoding.DecodeString(signMsgRes.Signature)
+			noNodeResolver, err := cmd.Flags().GetBool(clitypes.FlagNoNodeResolver)
 			if err != nil {
 				return err
 			}
 
-			var (
-				resp     clitypes.RestResponseBody
-				endpoint = fmt.Sprintf(
-					"%s/accounts/%s/sessions/%d",
-					strings.Trim(node.RemoteURL, "/"), from, session.Id,
-				)
-			)
+			include, err := parseIPNetsFromCmd(cmd, clitypes.FlagInclude)
+			if err != nil {
+				return err
+			}
 
-			buf, err := json.Marshal(
-				map[string]interface{}{
-					"key":       wgPrivateKey.Public().String(),
-					"signature": signature,
-				},
-			)
+			exclude, err := parseIPNetsFromCmd(cmd, clitypes.FlagExclude)
 			if err != nil {
 				return err
 			}
 
-			r, err := tc.Post(endpoint, jsonrpc.ContentType, bytes.NewBuffer(buf))
+			tc, err := context.NewTxContextFromCmd(cmd)
 			if err != nil {
 				return err
 			}
 
-			if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+			sc, err := context.NewServiceContextFromCmd(cmd)
+			if err != nil {
 				return err
 			}
-			if resp.Error != nil {
-				return fmt.Errorf(resp.Error.Message)
+
+			var nodeAddrs []hubtypes.NodeAddress
+			if auto {
+				nodes, err := queryAutoNodes(cmd, &tc, id, mode)
+				if err != nil {
+					return err
+				}
+
+				for i := 0; i < len(nodes) && i < autoConnectAttempts; i++ {
+					nodeAddr, err := hubtypes.NodeAddressFromBech32(nodes[i].Address)
+					if err != nil {
+						return err
+					}
+
+					nodeAddrs = append(nodeAddrs, nodeAddr)
+				}
+			} else {
+				nodeAddr, err := hubtypes.NodeAddressFromBech32(args[1])
+				if err != nil {
+					return err
+				}
+
+				nodeAddrs = append(nodeAddrs, nodeAddr)
 			}
 
-			info, err := base64.StdEncoding.DecodeString(resp.Result.(string))
+			reader := bufio.NewReader(cmd.InOrStdin())
+
+			password, from, err := tc.GetPasswordAndAddress(reader, tc.From)
 			if err != nil {
 				return err
 			}
 
-			return sc.Connect(
-				tc.Backend,
-				password,
-				from.String(),
-				nodeAddr.String(),
-				session.Id,
-				info,
-				[][]byte{wgPrivateKey.Bytes()},
-				resolvers,
-			)
+			req := &restrequests.ConnectToNode{
+				Backend:             tc.Backend,
+				Password:            password,
+				Name:                name,
+				Subscription:        id,
+				From:                from.String(),
+				Rating:              rating,
+				Resolvers:           resolvers,
+				NoNodeResolver:      noNodeResolver,
+				Include:             include,
+				Exclude:             exclude,
+				KillSwitch:          killSwitch,
+				Mode:                mode,
+				ProxyListen:         proxyListen,
+				ServiceBackend:      serviceBackend,
+				IFace:               iFace,
+				MTU:                 mtu,
+				PersistentKeepalive: keepalive,
+				DNSSearch:           dnsSearch,
+				MaxBytes:            maxBytes,
+				MaxDuration:         maxDuration,
+				EndSession:          endSession,
+				Tx:                  newTxRequest(&tc),
+			}
+
+			for i, nodeAddr := range nodeAddrs {
+				req.Node = nodeAddr.String()
+
+				var step string
+				err = sc.ConnectToNode(req, func(event clitypes.Event) error {
+					if event.State == clitypes.StepStateFailed && step == "" {
+						step = event.Step
+					}
+
+					printEvent(cmd, event)
+					return nil
+				})
+				if err == nil {
+					return nil
+				}
+				if step != clitypes.StepHandshake || i == len(nodeAddrs)-1 {
+					return err
+				}
+
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Handshake with node %s failed: %s, trying the next node\n", nodeAddr, err)
+			}
+
+			return nil
 		},
 	}
 
 	clitypes.AddServiceFlagsToCmd(cmd)
 	clitypes.AddTxFlagsToCmd(cmd)
 
+	cmd.Flags().Bool(clitypes.FlagAuto, false, "select the best node the subscription allows")
+	cmd.Flags().String(clitypes.FlagCountry, "", "filter the nodes of --auto with country")
+	cmd.Flags().StringArray(clitypes.FlagDNSSearch, nil, "provide DNS search domains")
+	cmd.Flags().Bool(clitypes.FlagEndSession, false, "end the session on-chain when a limit is reached")
+	cmd.Flags().StringArray(clitypes.FlagExclude, nil, "route the CIDR outside the tunnel")
+	cmd.Flags().String(clitypes.FlagInterface, "", "name of the WireGuard interface (default next free wgN)")
+	cmd.Flags().Uint16(clitypes.FlagKeepalive, wireguardtypes.DefaultPersistentKeepalive, "persistent keepalive interval in seconds, 0 to disable")
+	cmd.Flags().Bool(clitypes.FlagKillSwitch, false, "block the traffic outside the tunnel until disconnect")
+	cmd.Flags().String(clitypes.FlagMaxBytes, "", "disconnect after transferring the amount of data (e.g. 5GB)")
+	cmd.Flags().Duration(clitypes.FlagMaxDuration, 0, "disconnect after the connection lasts the duration (e.g. 2h)")
+	cmd.Flags().String(clitypes.FlagMaxPrice, "", "filter the nodes of --auto with maximum price per gigabyte (e.g. 100udvpn)")
+	cmd.Flags().String(clitypes.FlagMode, clitypes.ServiceModeTunnel, "connect with mode (tunnel|proxy)")
+	cmd.Flags().Uint16(clitypes.FlagMTU, 0, "MTU of the WireGuard interface (default 1420)")
+	cmd.Flags().String(clitypes.FlagName, clitypes.DefaultConnection, "name of the connection")
+	cmd.Flags().StringArray(clitypes.FlagInclude, nil, "route the CIDR through the tunnel (default all)")
+	cmd.Flags().Bool(clitypes.FlagNoNodeResolver, false, "do not use the DNS server of the node (10.8.0.1), only the ones of --resolver")
+	cmd.Flags().Uint64(clitypes.FlagPlan, 0, "filter the nodes of --auto with plan")
+	cmd.Flags().String(clitypes.FlagProvider, "", "filter the nodes of --auto with provider address")
+	cmd.Flags().String(clitypes.FlagProxyListen, clitypes.DefaultProxyListen, "loopback listen address of the SOCKS5 and HTTP proxy of mode proxy")
 	cmd.Flags().StringArray(clitypes.FlagResolver, nil, "provide additional DNS servers")
 	cmd.Flags().Uint64(clitypes.FlagRating, 0, "rate the session quality between 0 and 10")
+	cmd.Flags().String(clitypes.FlagServiceBackend, "", "WireGuard backend of the connection (wg-quick|netlink|userspace, default of the server)")
 
 	return cmd
 }
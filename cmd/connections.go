@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/sentinel-official/cli-client/context"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+var (
+	connectionsHeader = []string{
+		"Name",
+		"ID",
+		"Interface",
+		"Proxy",
+		"Up",
+		"Upload",
+		"Download",
+		"Kill switch",
+	}
+)
+
+func ConnectionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "connections",
+		Short: "List the connections of the management server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc, err := context.NewServiceContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			items, err := sc.ListConnections()
+			if err != nil {
+				return err
+			}
+
+			table := tablewriter.NewWriter(cmd.OutOrStdout())
+			table.SetHeader(connectionsHeader)
+
+			for _, item := range items {
+				table.Append(
+					[]string{
+						item.Name,
+						fmt.Sprintf("%d", item.ID),
+						item.IFace,
+						item.Proxy,
+						fmt.Sprintf("%t", item.Up),
+						clitypes.ToReadableBytes(item.Upload, 2),
+						clitypes.ToReadableBytes(item.Download, 2),
+						fmt.Sprintf("%t", item.KillSwitch),
+					},
+				)
+			}
+
+			table.Render()
+			return nil
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+	clitypes.AddTimeoutFlagsToCmd(cmd)
+
+	return cmd
+}
@@ -1,31 +1,113 @@
 package cmd
 
 import (
+	"bufio"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	sessiontypes "github.com/sentinel-official/hub/x/session/types"
+
 	"github.com/sentinel-official/cli-client/context"
 	clitypes "github.com/sentinel-official/cli-client/types"
 )
 
+func endSession(cmd *cobra.Command, tc *context.TxContext, password string, from sdk.AccAddress, id, rating uint64) error {
+	session, err := tc.QueryActiveSession(from)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.Id != id {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Session %d is not active, skipping the end request\n", id)
+		return nil
+	}
+
+	txRes, err := tc.SignMessagesAndBroadcastTx(
+		password,
+		sessiontypes.NewMsgEndRequest(
+			from,
+			id,
+			rating,
+		),
+	)
+	if err != nil {
+		return err
+	}
+	if txRes.Code != 0 {
+		return errors.New(txRes.RawLog)
+	}
+
+	fmt.Println(txRes)
+	return nil
+}
+
+func endSessionFromCmd(cmd *cobra.Command, id, rating uint64) error {
+	for _, flag := range []string{clitypes.FlagChainID, clitypes.FlagFrom, clitypes.FlagRPCAddress} {
+		if !cmd.Flags().Changed(flag) {
+			return fmt.Errorf("flag --%s is required to end it", flag)
+		}
+	}
+
+	tc, err := context.NewTxContextFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(cmd.InOrStdin())
+
+	password, from, err := tc.GetPasswordAndAddress(reader, tc.From)
+	if err != nil {
+		return err
+	}
+
+	return endSession(cmd, &tc, password, from, id, rating)
+}
+
 func DisconnectCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "disconnect",
 		Short: "Disconnect from a node",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := cmd.Flags().GetString(clitypes.FlagName)
+			if err != nil {
+				return err
+			}
+
+			rating, err := cmd.Flags().GetUint64(clitypes.FlagRating)
+			if err != nil {
+				return err
+			}
+
+			keepSession, err := cmd.Flags().GetBool(clitypes.FlagKeepSession)
+			if err != nil {
+				return err
+			}
+
 			sc, err := context.NewServiceContextFromCmd(cmd)
 			if err != nil {
 				return err
 			}
 
-			status, err := sc.GetStatus()
+			status, err := sc.GetStatus(name)
 			if err != nil {
 				return err
 			}
 
-			if status.IFace != "" {
-				if err := sc.Disconnect(); err != nil {
-					return err
-				}
+			if status.IFace == "" {
+				return nil
+			}
+
+			if err := sc.Disconnect(name); err != nil {
+				return err
+			}
+			if keepSession || status.ID == 0 {
+				return nil
+			}
+
+			if err := endSessionFromCmd(cmd, status.ID, rating); err != nil {
+				return errors.Wrapf(err, "session %d is still active on-chain", status.ID)
 			}
 
 			return nil
@@ -33,7 +115,11 @@ func DisconnectCmd() *cobra.Command {
 	}
 
 	clitypes.AddServiceFlagsToCmd(cmd)
-	clitypes.AddTimeoutFlagsToCmd(cmd)
+	clitypes.AddOptionalTxFlagsToCmd(cmd)
+
+	cmd.Flags().Bool(clitypes.FlagKeepSession, false, "keep the session active on-chain")
+	cmd.Flags().String(clitypes.FlagName, clitypes.DefaultConnection, "name of the connection")
+	cmd.Flags().Uint64(clitypes.FlagRating, 0, "rate the session quality between 0 and 10")
 
 	return cmd
 }
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	hubtypes "github.com/sentinel-official/hub/types"
+	"github.com/spf13/cobra"
+
+	"github.com/sentinel-official/cli-client/context"
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func runInNamespace(cmd *cobra.Command, namespace string, args []string) (int, error) {
+	c := exec.Command("ip", append([]string{"netns", "exec", namespace}, args...)...)
+	c.Stdin = cmd.InOrStdin()
+	c.Stdout = cmd.OutOrStdout()
+	c.Stderr = cmd.ErrOrStderr()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := c.Start(); err != nil {
+		return 0, err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.Wait() }()
+
+	for {
+		select {
+		case sig := <-signals:
+			_ = c.Process.Signal(sig)
+		case err := <-done:
+			var e *exec.ExitError
+			if errors.As(err, &e) {
+				return e.ExitCode(), nil
+			}
+
+			return 0, err
+		}
+	}
+}
+
+func ExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec [command] [args...]",
+		Short: "Run a command through a node in an isolated network namespace",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := cmd.Flags().GetUint64(clitypes.FlagSubscription)
+			if err != nil {
+				return err
+			}
+
+			s, err := cmd.Flags().GetString(clitypes.FlagNode)
+			if err != nil {
+				return err
+			}
+
+			nodeAddr, err := hubtypes.NodeAddressFromBech32(s)
+			if err != nil {
+				return err
+			}
+
+			rating, err := cmd.Flags().GetUint64(clitypes.FlagRating)
+			if err != nil {
+				return err
+			}
+
+			name, err := cmd.Flags().GetString(clitypes.FlagName)
+			if err != nil {
+				return err
+			}
+
+			iFace, err := cmd.Flags().GetString(clitypes.FlagInterface)
+			if err != nil {
+				return err
+			}
+
+			mtu, err := cmd.Flags().GetUint16(clitypes.FlagMTU)
+			if err != nil {
+				return err
+			}
+
+			keepalive, err := cmd.Flags().GetUint16(clitypes.FlagKeepalive)
+			if err != nil {
+				return err
+			}
+
+			dnsSearch, err := cmd.Flags().GetStringArray(clitypes.FlagDNSSearch)
+			if err != nil {
+				return err
+			}
+
+			keepSession, err := cmd.Flags().GetBool(clitypes.FlagKeepSession)
+			if err != nil {
+				return err
+			}
+
+			resolvers, err := parseResolversFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			noNodeResolver, err := cmd.Flags().GetBool(clitypes.FlagNoNodeResolver)
+			if err != nil {
+				return err
+			}
+
+			tc, err := context.NewTxContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			sc, err := context.NewServiceContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			status, err := sc.GetStatus(name)
+			if err != nil {
+				return err
+			}
+			if status.IFace != "" {
+				return fmt.Errorf("connection %s is already running on interface %s", name, status.IFace)
+			}
+
+			reader := bufio.NewReader(cmd.InOrStdin())
+
+			password, from, err := tc.GetPasswordAndAddress(reader, tc.From)
+			if err != nil {
+				return err
+			}
+
+			var (
+				code    int
+				session uint64
+				runErr  = sc.ConnectToNode(
+					&restrequests.ConnectToNode{
+						Backend:             tc.Backend,
+						Password:            password,
+						Name:                name,
+						Subscription:        id,
+						From:                from.String(),
+						Node:                nodeAddr.String(),
+						Rating:              rating,
+						Resolvers:           resolvers,
+						NoNodeResolver:      noNodeResolver,
+						Mode:                clitypes.ServiceModeNamespace,
+						IFace:               iFace,
+						MTU:                 mtu,
+						PersistentKeepalive: keepalive,
+						DNSSearch:           dnsSearch,
+						Tx:                  newTxRequest(&tc),
+					},
+					func(event clitypes.Event) error {
+						if event.Session != 0 {
+							session = event.Session
+						}
+
+						printEvent(cmd, event)
+						return nil
+					},
+				)
+			)
+			if runErr != nil {
+				return runErr
+			}
+
+			status, runErr = sc.GetStatus(name)
+			if runErr == nil && status.Namespace == "" {
+				runErr = fmt.Errorf("connection %s is not running in a network namespace", name)
+			}
+			if runErr == nil {
+				code, runErr = runInNamespace(cmd, status.Namespace, args)
+			}
+
+			errs := []error{runErr}
+			if err := sc.Disconnect(name); err != nil {
+				errs = append(errs, fmt.Errorf("failed to disconnect %s: %w", name, err))
+			}
+			if !keepSession && session != 0 {
+				if err := endSession(cmd, &tc, password, from, session, rating); err != nil {
+					errs = append(errs, fmt.Errorf("session %d is still active on-chain: %w", session, err))
+				}
+			}
+			if err := errors.Join(errs...); err != nil {
+				return err
+			}
+			if code != 0 {
+				cmd.SilenceErrors = true
+				return &ExitError{Code: code}
+			}
+
+			return nil
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+	clitypes.AddTxFlagsToCmd(cmd)
+
+	cmd.Flags().SetInterspersed(false)
+
+	cmd.Flags().StringArray(clitypes.FlagDNSSearch, nil, "provide DNS search domains")
+	cmd.Flags().String(clitypes.FlagInterface, "", "name of the WireGuard interface (default next free wgN)")
+	cmd.Flags().Uint16(clitypes.FlagKeepalive, wireguardtypes.DefaultPersistentKeepalive, "persistent keepalive interval in seconds, 0 to disable")
+	cmd.Flags().Bool(clitypes.FlagKeepSession, false, "keep the session active on-chain after the command exits")
+	cmd.Flags().Uint16(clitypes.FlagMTU, 0, "MTU of the WireGuard interface (default 1420)")
+	cmd.Flags().String(clitypes.FlagName, clitypes.DefaultExecConnection, "name of the connection")
+	cmd.Flags().String(clitypes.FlagNode, "", "address of the node")
+	cmd.Flags().Bool(clitypes.FlagNoNodeResolver, false, "do not use the DNS server of the node (10.8.0.1), only the ones of --resolver")
+	cmd.Flags().Uint64(clitypes.FlagRating, 0, "rate the session quality between 0 and 10")
+	cmd.Flags().StringArray(clitypes.FlagResolver, nil, "provide additional DNS servers")
+	cmd.Flags().Uint64(clitypes.FlagSubscription, 0, "subscription of the session")
+
+	_ = cmd.MarkFlagRequired(clitypes.FlagNode)
+	_ = cmd.MarkFlagRequired(clitypes.FlagSubscription)
+
+	return cmd
+}
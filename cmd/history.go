@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/sentinel-official/cli-client/context"
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+var (
+	historyHeader = []string{
+		"Name",
+		"ID",
+		"Node",
+		"Start",
+		"End",
+		"Upload",
+		"Download",
+		"Reason",
+		"Errors",
+	}
+)
+
+func parseHistoryTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+func HistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the finished connections of the management server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := cmd.Flags().GetString(clitypes.FlagName)
+			if err != nil {
+				return err
+			}
+
+			node, err := cmd.Flags().GetString(clitypes.FlagNode)
+			if err != nil {
+				return err
+			}
+
+			output, err := cmd.Flags().GetString(clitypes.FlagOutput)
+			if err != nil {
+				return err
+			}
+
+			reason, err := cmd.Flags().GetString(clitypes.FlagReason)
+			if err != nil {
+				return err
+			}
+
+			since, err := cmd.Flags().GetString(clitypes.FlagSince)
+			if err != nil {
+				return err
+			}
+
+			until, err := cmd.Flags().GetString(clitypes.FlagUntil)
+			if err != nil {
+				return err
+			}
+
+			req := &restrequests.GetHistory{
+				Name:   name,
+				Node:   node,
+				Reason: reason,
+			}
+
+			req.Since, err = parseHistoryTime(since)
+			if err != nil {
+				return err
+			}
+
+			req.Until, err = parseHistoryTime(until)
+			if err != nil {
+				return err
+			}
+
+			if err := req.Validate(); err != nil {
+				return err
+			}
+
+			sc, err := context.NewServiceContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			items, err := sc.GetHistory(req)
+			if err != nil {
+				return err
+			}
+
+			switch output {
+			case "json":
+				return items.WriteJSON(cmd.OutOrStdout())
+			case "csv":
+				return items.WriteCSV(cmd.OutOrStdout())
+			case "table":
+			default:
+				return fmt.Errorf("invalid output %s", output)
+			}
+
+			table := tablewriter.NewWriter(cmd.OutOrStdout())
+			table.SetHeader(historyHeader)
+
+			for _, item := range items {
+				table.Append(
+					[]string{
+						item.Name,
+						fmt.Sprintf("%d", item.ID),
+						item.Node,
+						item.StartAt.Local().Format(time.RFC3339),
+						item.EndAt.Local().Format(time.RFC3339),
+						clitypes.ToReadableBytes(item.Upload, 2),
+						clitypes.ToReadableBytes(item.Download, 2),
+						item.Reason,
+						fmt.Sprintf("%d", len(item.Errors)),
+					},
+				)
+			}
+
+			table.Render()
+			return nil
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+	clitypes.AddTimeoutFlagsToCmd(cmd)
+
+	cmd.Flags().String(clitypes.FlagName, "", "filter by the name of the connection")
+	cmd.Flags().String(clitypes.FlagNode, "", "filter by the address of the node")
+	cmd.Flags().String(clitypes.FlagOutput, "table", "output format (table|json|csv)")
+	cmd.Flags().String(clitypes.FlagReason, "", "filter by the disconnect reason (cleanup|disconnect|failover|limit)")
+	cmd.Flags().String(clitypes.FlagSince, "", "list connections that ended after this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().String(clitypes.FlagUntil, "", "list connections that started before this time (RFC3339 or YYYY-MM-DD)")
+
+	return cmd
+}
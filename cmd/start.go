@@ -1,19 +1,35 @@
 package cmd
 
 import (
+	"bufio"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"os/user"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 	"github.com/rs/cors"
 	"github.com/spf13/cobra"
 
 	"github.com/sentinel-official/cli-client/context"
 	restmiddlewares "github.com/sentinel-official/cli-client/rest/middlewares"
 	restmodules "github.com/sentinel-official/cli-client/rest/modules"
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	restroutes "github.com/sentinel-official/cli-client/rest/routes"
 	clitypes "github.com/sentinel-official/cli-client/types"
+	cliutils "github.com/sentinel-official/cli-client/utils"
 )
 
 func StartCmd() *cobra.Command {
@@ -58,14 +74,173 @@ func StartCmd() *cobra.Command {
 				return err
 			}
 
+			withQuery, err := cmd.Flags().GetBool(clitypes.FlagWithQuery)
+			if err != nil {
+				return err
+			}
+
 			withService, err := cmd.Flags().GetBool(clitypes.FlagWithService)
 			if err != nil {
 				return err
 			}
 
+			sampleInterval, err := cmd.Flags().GetDuration(clitypes.FlagSampleInterval)
+			if err != nil {
+				return err
+			}
+
+			limitInterval, err := cmd.Flags().GetDuration(clitypes.FlagLimitInterval)
+			if err != nil {
+				return err
+			}
+
+			reconnectInterval, err := cmd.Flags().GetDuration(clitypes.FlagReconnectInterval)
+			if err != nil {
+				return err
+			}
+
+			reconnectNodes, err := cmd.Flags().GetStringArray(clitypes.FlagReconnectNodes)
+			if err != nil {
+				return err
+			}
+
+			reconnectRetries, err := cmd.Flags().GetInt(clitypes.FlagReconnectRetries)
+			if err != nil {
+				return err
+			}
+
+			reconnectTimeout, err := cmd.Flags().GetDuration(clitypes.FlagReconnectTimeout)
+			if err != nil {
+				return err
+			}
+
+			wireGuardBackend, err := cmd.Flags().GetString(clitypes.FlagWireGuardBackend)
+			if err != nil {
+				return err
+			}
+
+			allowedHosts, err := cmd.Flags().GetStringArray(clitypes.FlagAllowedHosts)
+			if err != nil {
+				return err
+			}
+
+			allowedOrigins, err := cmd.Flags().GetStringArray(clitypes.FlagAllowedOrigins)
+			if err != nil {
+				return err
+			}
+
+			socketMode, err := cmd.Flags().GetString(clitypes.FlagSocketMode)
+			if err != nil {
+				return err
+			}
+
+			socketOwner, err := cmd.Flags().GetString(clitypes.FlagSocketOwner)
+			if err != nil {
+				return err
+			}
+
+			keyringUIDs, err := cmd.Flags().GetUintSlice(clitypes.FlagKeyringUIDs)
+			if err != nil {
+				return err
+			}
+
+			queryUIDs, err := cmd.Flags().GetUintSlice(clitypes.FlagQueryUIDs)
+			if err != nil {
+				return err
+			}
+
+			serviceUIDs, err := cmd.Flags().GetUintSlice(clitypes.FlagServiceUIDs)
+			if err != nil {
+				return err
+			}
+			if len(keyringUIDs)+len(queryUIDs)+len(serviceUIDs) > 0 && !cliutils.PeerCredentialsSupported {
+				return errors.New("uid allowlists are not supported on this platform")
+			}
+
+			withTLS, err := cmd.Flags().GetBool(clitypes.FlagTLS)
+			if err != nil {
+				return err
+			}
+
+			tlsCert, err := cmd.Flags().GetString(clitypes.FlagTLSCert)
+			if err != nil {
+				return err
+			}
+
+			tlsKey, err := cmd.Flags().GetString(clitypes.FlagTLSKey)
+			if err != nil {
+				return err
+			}
+			if (tlsCert == "") != (tlsKey == "") {
+				return fmt.Errorf("flags --%s and --%s must be provided together", clitypes.FlagTLSCert, clitypes.FlagTLSKey)
+			}
+			if tlsCert != "" {
+				withTLS = true
+			}
+			if withTLS && strings.HasPrefix(listen, clitypes.UnixScheme+"://") {
+				return errors.New("tls is not supported on a unix socket")
+			}
+
+			allowConfigHooks, err := cmd.Flags().GetBool(clitypes.FlagAllowConfigHooks)
+			if err != nil {
+				return err
+			}
+
+			endSession, err := cmd.Flags().GetBool(clitypes.FlagEndSession)
+			if err != nil {
+				return err
+			}
+
+			onStart, err := cmd.Flags().GetString(clitypes.FlagOnStart)
+			if err != nil {
+				return err
+			}
+			if onStart != clitypes.OnStartRestore && onStart != clitypes.OnStartCleanup && onStart != clitypes.OnStartIgnore {
+				return fmt.Errorf("on-start must be either %s, %s or %s", clitypes.OnStartRestore, clitypes.OnStartCleanup, clitypes.OnStartIgnore)
+			}
+
+			ctx := context.NewServerContext().
+				WithHome(home).
+				WithClient(client.GetClientContextFromCmd(cmd)).
+				WithConfigHooks(allowConfigHooks).
+				WithEndSessions(endSession)
+
+			if wireGuardBackend == clitypes.ServiceBackendUserspace {
+				return fmt.Errorf("backend %s is supported only by mode proxy", wireGuardBackend)
+			}
+			if wireGuardBackend != "" {
+				if err := ctx.Registry().SetDefault(clitypes.ServiceTypeWireGuard, wireGuardBackend); err != nil {
+					return err
+				}
+			}
+
+			signer, err := newSignerFromCmd(cmd, ctx)
+			if err != nil {
+				return err
+			}
+
+			ctx = ctx.WithSigner(signer)
+
+			broker := context.NewBroker(ctx).
+				WithInterval(sampleInterval)
+
+			ctx = ctx.WithBroker(broker)
+
+			supervisor := context.NewSupervisor(ctx).
+				WithInterval(reconnectInterval).
+				WithNodes(reconnectNodes).
+				WithRetries(reconnectRetries).
+				WithTimeout(reconnectTimeout)
+
+			limiter := context.NewLimiter(ctx.WithSupervisor(supervisor)).
+				WithInterval(limitInterval)
+
+			token, err := cliutils.RandomToken(clitypes.TokenLength)
+			if err != nil {
+				return err
+			}
+
 			var (
-				ctx = context.NewServerContext().
-					WithHome(home)
 				muxRouter    = mux.NewRouter()
 				prefixRouter = muxRouter.
 						PathPrefix(clitypes.APIPathPrefix).
@@ -73,18 +248,95 @@ func StartCmd() *cobra.Command {
 			)
 
 			muxRouter.Use(restmiddlewares.Log)
+			muxRouter.Use(restmiddlewares.CheckHost(allowedHosts))
+			muxRouter.Use(restmiddlewares.CheckOrigin(allowedOrigins))
 			prefixRouter.Use(restmiddlewares.AddHeaders)
+			prefixRouter.Use(
+				restmiddlewares.Authenticate(
+					token,
+					map[string][]uint32{
+						restroutes.ModuleKeyring: toUIDs(keyringUIDs),
+						restroutes.ModuleQuery:   toUIDs(queryUIDs),
+						restroutes.ModuleService: toUIDs(serviceUIDs),
+					},
+				),
+			)
 
 			if withKeyring {
 				restmodules.RegisterKeyring(prefixRouter, &ctx)
 			}
+
+			rpcAddress, err := cmd.Flags().GetString(clitypes.FlagRPCAddress)
+			if err != nil {
+				return err
+			}
+			if rpcAddress != "" {
+				qc, err := context.NewQueryContext(ctx.Client(), rpcAddress)
+				if err != nil {
+					return err
+				}
+
+				ctx = ctx.WithQuery(&qc)
+			}
+
+			if withQuery {
+				if rpcAddress == "" {
+					return fmt.Errorf("flag --%s is required by --%s", clitypes.FlagRPCAddress, clitypes.FlagWithQuery)
+				}
+
+				restmodules.RegisterQuery(prefixRouter, &ctx)
+			}
 			if withService {
+				ctx = ctx.WithSupervisor(supervisor).
+					WithLimiter(limiter)
 				restmodules.RegisterService(prefixRouter, &ctx)
+
+				if err := ctx.Reconcile(onStart); err != nil {
+					return err
+				}
+
+				go broker.Start()
+				go supervisor.Start()
+				go limiter.Start()
+			}
+
+			listener, err := newListener(listen, socketMode, socketOwner)
+			if err != nil {
+				return err
+			}
+
+			u := "http" + "://" + listen + clitypes.APIPathPrefix
+			if strings.HasPrefix(listen, clitypes.UnixScheme+"://") {
+				u = listen
+			}
+
+			if withTLS {
+				if tlsCert == "" {
+					tlsCert, tlsKey, err = ensureCertificate(home, listen, allowedHosts)
+					if err != nil {
+						return err
+					}
+				}
+
+				fingerprint, err := cliutils.CertificateFingerprint(tlsCert)
+				if err != nil {
+					return err
+				}
+
+				u = "https" + "://" + listen + clitypes.APIPathPrefix + "\n" + fingerprint
+			}
+
+			tokenFilePath := filepath.Join(home, clitypes.TokenFilename)
+			if err := os.Remove(tokenFilePath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err := os.WriteFile(tokenFilePath, []byte(token), 0600); err != nil {
+				return err
 			}
 
 			if err := os.WriteFile(
-				filepath.Join(home, "url.txt"),
-				[]byte("http"+"://"+listen+clitypes.APIPathPrefix),
+				filepath.Join(home, clitypes.URLFilename),
+				[]byte(u),
 				os.ModePerm,
 			); err != nil {
 				return err
@@ -92,22 +344,305 @@ func StartCmd() *cobra.Command {
 
 			router := cors.New(
 				cors.Options{
-					AllowedOrigins: []string{"*"},
-					AllowedMethods: []string{http.MethodPost},
-					AllowedHeaders: []string{"Content-Type"},
+					AllowOriginFunc: func(origin string) bool {
+						return restmiddlewares.IsAllowedOrigin(allowedOrigins, origin)
+					},
+					AllowedMethods: []string{http.MethodGet, http.MethodPost},
+					AllowedHeaders: []string{"Authorization", "Content-Type"},
 				},
 			).Handler(muxRouter)
 
-			log.Printf("Listening on %s", listen)
-			return http.ListenAndServe(listen, router)
+			var (
+				server = &http.Server{
+					Handler:     router,
+					ConnContext: restmiddlewares.ConnContext,
+				}
+				errs    = make(chan error, 1)
+				signals = make(chan os.Signal, 1)
+			)
+
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+			go func() {
+				log.Printf("Listening on %s", listen)
+				if withTLS {
+					errs <- server.ServeTLS(listener, tlsCert, tlsKey)
+				} else {
+					errs <- server.Serve(listener)
+				}
+			}()
+
+			select {
+			case err := <-errs:
+				return err
+			case sig := <-signals:
+				log.Printf("Received signal %s, shutting down", sig)
+			}
+
+			if err := server.Close(); err != nil {
+				return err
+			}
+
+			if withService {
+				if err := ctx.Shutdown(); err != nil {
+					log.Printf("Failed to end the sessions: %s", err)
+				}
+			}
+
+			return nil
 		},
 	}
 
+	cmd.Flags().Bool(clitypes.FlagAllowConfigHooks, false, "allow the imported configs to run PreUp, PostUp, PreDown and PostDown commands as the server user")
+	cmd.Flags().StringArray(clitypes.FlagAllowedHosts, nil, "additional host names accepted in the Host header (IP addresses and localhost are always accepted)")
+	cmd.Flags().StringArray(clitypes.FlagAllowedOrigins, nil, "origins allowed to make cross-origin requests (e.g. http://localhost:3000)")
+	cmd.Flags().String(clitypes.FlagBroadcastMode, flags.BroadcastBlock, "transaction broadcasting mode of the session end on shutdown (async|block|sync)")
+	cmd.Flags().String(clitypes.FlagChainID, "", "chain identity of the network")
+	cmd.Flags().Bool(clitypes.FlagEndSession, false, "tear down the connections and end their sessions on shutdown with the credentials they were started with")
+	cmd.Flags().String(clitypes.FlagFrom, "", "name or address of private key with which to end the sessions of the connections started without tx settings on shutdown")
+	cmd.Flags().Uint64(clitypes.FlagGas, flags.DefaultGasLimit, "gas limit to set per-transaction")
+	cmd.Flags().String(clitypes.FlagGasPrices, "", "gas prices in decimal format to determine the transaction fee")
+	cmd.Flags().String(clitypes.FlagKeyringBackend, keyring.BackendOS, "the keyring backend (file|os|test)")
+	cmd.Flags().UintSlice(clitypes.FlagKeyringUIDs, nil, "uids allowed to call the keyring endpoints over the unix socket (Linux only)")
+	cmd.Flags().String(clitypes.FlagRPCAddress, "", "tendermint RPC interface address for this chain, also used to verify the sessions of the restored connections")
 	cmd.Flags().Bool(clitypes.FlagWithKeyring, false, "include the endpoints of keyring module")
+	cmd.Flags().Bool(clitypes.FlagWithQuery, false, "include the endpoints of query module (requires --rpc-address)")
 	cmd.Flags().Bool(clitypes.FlagWithService, false, "include the endpoints of service module")
 	cmd.Flags().Bool(clitypes.FlagTTY, false, "enable the standard error, input and output")
-	cmd.Flags().String(clitypes.FlagListen, clitypes.Listen, "listen address of the server")
+	cmd.Flags().Bool(clitypes.FlagTLS, false, "serve over TLS with a self-signed certificate generated in the home directory")
+	cmd.Flags().String(clitypes.FlagTLSCert, "", "path of the TLS certificate (implies --tls)")
+	cmd.Flags().String(clitypes.FlagTLSKey, "", "path of the TLS private key (implies --tls)")
+	cmd.Flags().Duration(clitypes.FlagSampleInterval, clitypes.SampleInterval, "interval between the bandwidth samples of the status stream and the rates of the status")
+	cmd.Flags().Duration(clitypes.FlagLimitInterval, clitypes.LimitInterval, "interval between the checks of the connection limits")
+	cmd.Flags().String(clitypes.FlagListen, clitypes.Listen, "listen address of the server (host:port or unix:///path/to/socket)")
+	cmd.Flags().String(clitypes.FlagOnStart, clitypes.OnStartRestore, "policy for the connections left by a previous run (restore|cleanup|ignore), restore keeps the healthy tunnels up")
 	cmd.Flags().String(clitypes.FlagHome, clitypes.Home, "home directory of the server")
+	cmd.Flags().Duration(clitypes.FlagReconnectInterval, clitypes.ReconnectInterval, "interval between the tunnel health checks (0 to disable)")
+	cmd.Flags().StringArray(clitypes.FlagReconnectNodes, nil, "alternate nodes to fail over to after the retries are exhausted")
+	cmd.Flags().Int(clitypes.FlagReconnectRetries, clitypes.ReconnectRetries, "reconnect attempts with the same node before failing over")
+	cmd.Flags().Duration(clitypes.FlagReconnectTimeout, clitypes.ReconnectTimeout, "time without a handshake or received bytes before reconnecting")
+	cmd.Flags().UintSlice(clitypes.FlagQueryUIDs, nil, "uids allowed to call the query endpoints over the unix socket (Linux only)")
+	cmd.Flags().UintSlice(clitypes.FlagServiceUIDs, nil, "uids allowed to call the service endpoints over the unix socket (Linux only)")
+	cmd.Flags().String(clitypes.FlagSocketMode, "0660", "file permissions of the unix socket")
+	cmd.Flags().String(clitypes.FlagSocketOwner, "", "owner of the unix socket as user[:group]")
+	cmd.Flags().String(clitypes.FlagWireGuardBackend, "", "default WireGuard backend of mode tunnel (wg-quick|netlink, default netlink when supported)")
 
 	return cmd
 }
+
+func newSignerFromCmd(cmd *cobra.Command, ctx context.ServerContext) (*context.Signer, error) {
+	from, err := cmd.Flags().GetString(clitypes.FlagFrom)
+	if err != nil {
+		return nil, err
+	}
+	if from == "" {
+		return nil, nil
+	}
+
+	backend, err := cmd.Flags().GetString(clitypes.FlagKeyringBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMode, err := cmd.Flags().GetString(clitypes.FlagBroadcastMode)
+	if err != nil {
+		return nil, err
+	}
+
+	chainID, err := cmd.Flags().GetString(clitypes.FlagChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	gas, err := cmd.Flags().GetUint64(clitypes.FlagGas)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrices, err := cmd.Flags().GetString(clitypes.FlagGasPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcAddress, err := cmd.Flags().GetString(clitypes.FlagRPCAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &restrequests.Tx{
+		BroadcastMode: broadcastMode,
+		ChainID:       chainID,
+		Gas:           gas,
+		GasPrices:     gasPrices,
+		RPCAddress:    rpcAddress,
+	}
+
+	if err := tx.Validate(); err != nil {
+		return nil, err
+	}
+
+	tty, err := cmd.Flags().GetBool(clitypes.FlagTTY)
+	if err != nil {
+		return nil, err
+	}
+	if backend == keyring.BackendFile && !tty {
+		return nil, errors.New("keyring backend file requires the flag --tty to read the password")
+	}
+
+	password, err := cliutils.GetPassword(backend, bufio.NewReader(cmd.InOrStdin()))
+	if err != nil {
+		return nil, err
+	}
+
+	kr, err := ctx.Keyring(backend, password)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := kr.Key(from)
+	if err != nil {
+		accAddr, bech32Err := sdk.AccAddressFromBech32(from)
+		if bech32Err != nil {
+			return nil, err
+		}
+
+		key, err = kr.KeyByAddress(accAddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &context.Signer{
+		Backend:  backend,
+		Password: password,
+		From:     key.GetAddress().String(),
+		Tx:       tx,
+	}, nil
+}
+
+func toUIDs(v []uint) []uint32 {
+	items := make([]uint32, 0, len(v))
+	for _, uid := range v {
+		items = append(items, uint32(uid))
+	}
+
+	return items
+}
+
+func lookupOwner(s string) (uid int, gid int, err error) {
+	name, group, _ := strings.Cut(s, ":")
+
+	uid, err = strconv.Atoi(name)
+	if err != nil {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if group == "" {
+		return uid, -1, nil
+	}
+
+	gid, err = strconv.Atoi(group)
+	if err != nil {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return uid, gid, nil
+}
+
+func ensureCertificate(home, listen string, hosts []string) (string, string, error) {
+	var (
+		certPath = filepath.Join(home, clitypes.TLSCertFilename)
+		keyPath  = filepath.Join(home, clitypes.TLSKeyFilename)
+	)
+
+	if _, err := os.Stat(certPath); err == nil {
+		if _, err := os.Stat(keyPath); err == nil {
+			return certPath, keyPath, nil
+		}
+	}
+
+	names := []string{"localhost", "127.0.0.1", "::1"}
+	if host, _, err := net.SplitHostPort(listen); err == nil {
+		if ip := net.ParseIP(host); host != "" && (ip == nil || !ip.IsUnspecified()) {
+			names = append(names, host)
+		}
+	}
+
+	certPEM, keyPEM, err := cliutils.GenerateCertificate(append(names, hosts...), clitypes.TLSCertificateValidity)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return "", "", err
+	}
+
+	log.Printf("Generated a self-signed TLS certificate %s", certPath)
+	return certPath, keyPath, nil
+}
+
+func newListener(listen, mode, owner string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(listen, clitypes.UnixScheme+"://")
+	if !ok {
+		return net.Listen("tcp", listen)
+	}
+	if path == "" {
+		return nil, errors.New("path of the unix socket cannot be empty")
+	}
+
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid socket mode %s", mode)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("file %s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, os.FileMode(perm)); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	if owner != "" {
+		uid, gid, err := lookupOwner(owner)
+		if err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+
+		if err := os.Chown(path, uid, gid); err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+	}
+
+	return listener, nil
+}
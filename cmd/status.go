@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/sentinel-official/cli-client/context"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+var (
+	statusHeader = []string{
+		"Name",
+		"ID",
+		"Interface",
+		"Proxy",
+		"Up",
+		"Upload",
+		"Download",
+		"Endpoint",
+		"Handshake",
+		"Kill switch",
+	}
+)
+
+func formatHandshakeAge(age *float64) string {
+	if age == nil {
+		return "never"
+	}
+
+	return fmt.Sprintf("%s ago", (time.Duration(*age) * time.Second).String())
+}
+
+func printEvent(cmd *cobra.Command, event clitypes.Event) {
+	var (
+		w = cmd.OutOrStdout()
+		t = event.Time.Local().Format("15:04:05")
+	)
+
+	switch event.Type {
+	case clitypes.EventTypeState:
+		if event.Error != "" {
+			_, _ = fmt.Fprintf(w, "%s %s %s: %s\n", t, event.Name, event.State, event.Error)
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, "%s %s %s\n", t, event.Name, event.State)
+	case clitypes.EventTypeStep:
+		if event.Error != "" {
+			_, _ = fmt.Fprintf(w, "%s %s %s %s: %s\n", t, event.Name, event.Step, event.State, event.Error)
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, "%s %s %s %s\n", t, event.Name, event.Step, event.State)
+	case clitypes.EventTypeSample:
+		_, _ = fmt.Fprintf(w, "%s %s upload %s (%s/s) download %s (%s/s)\n",
+			t, event.Name,
+			clitypes.ToReadableBytes(event.Upload, 2), clitypes.ToReadableBytes(int64(event.UploadRate), 2),
+			clitypes.ToReadableBytes(event.Download, 2), clitypes.ToReadableBytes(int64(event.DownloadRate), 2),
+		)
+	}
+}
+
+func StatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of a connection",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := cmd.Flags().GetString(clitypes.FlagName)
+			if err != nil {
+				return err
+			}
+
+			follow, err := cmd.Flags().GetBool(clitypes.FlagFollow)
+			if err != nil {
+				return err
+			}
+
+			sc, err := context.NewServiceContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			if follow {
+				return sc.StreamStatus(name, func(event clitypes.Event) error {
+					printEvent(cmd, event)
+					return nil
+				})
+			}
+
+			item, err := sc.GetStatus(name)
+			if err != nil {
+				return err
+			}
+
+			table := tablewriter.NewWriter(cmd.OutOrStdout())
+			table.SetHeader(statusHeader)
+
+			if item.Name != "" {
+				table.Append(
+					[]string{
+						item.Name,
+						fmt.Sprintf("%d", item.ID),
+						item.IFace,
+						item.Proxy,
+						fmt.Sprintf("%t", item.Up),
+						clitypes.ToReadableBytes(item.Upload, 2),
+						clitypes.ToReadableBytes(item.Download, 2),
+						item.Endpoint,
+						formatHandshakeAge(item.HandshakeAge),
+						fmt.Sprintf("%t", item.KillSwitch),
+					},
+				)
+			}
+
+			table.Render()
+			return nil
+		},
+	}
+
+	clitypes.AddServiceFlagsToCmd(cmd)
+	clitypes.AddTimeoutFlagsToCmd(cmd)
+
+	cmd.Flags().Bool(clitypes.FlagFollow, false, "stream the state transitions and bandwidth samples")
+	cmd.Flags().String(clitypes.FlagName, clitypes.DefaultConnection, "name of the connection")
+
+	return cmd
+}
@@ -0,0 +1,165 @@
+package context
+
+import (
+	"sync"
+	"time"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+const (
+	eventBufferSize = 64
+)
+
+type sample struct {
+	time         time.Time
+	upload       int64
+	download     int64
+	uploadRate   float64
+	downloadRate float64
+}
+
+type Broker struct {
+	ctx      ServerContext
+	interval time.Duration
+
+	mutex       sync.Mutex
+	subscribers map[chan clitypes.Event]struct{}
+	samples     map[string]sample
+}
+
+func NewBroker(ctx ServerContext) *Broker {
+	return &Broker{
+		ctx:         ctx,
+		subscribers: make(map[chan clitypes.Event]struct{}),
+		samples:     make(map[string]sample),
+	}
+}
+
+func (b *Broker) WithInterval(v time.Duration) *Broker { b.interval = v; return b }
+
+func (b *Broker) Subscribe() (<-chan clitypes.Event, func()) {
+	ch := make(chan clitypes.Event, eventBufferSize)
+
+	b.mutex.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mutex.Unlock()
+
+	return ch, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		delete(b.subscribers, ch)
+	}
+}
+
+func (b *Broker) Publish(e clitypes.Event) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (b *Broker) hasSubscribers() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.subscribers) > 0
+}
+
+func (b *Broker) Start() {
+	if b.interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.sample()
+	}
+}
+
+func (b *Broker) Rates(name string) (uploadRate, downloadRate float64) {
+	if b == nil {
+		return 0, 0
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	v := b.samples[name]
+	return v.uploadRate, v.downloadRate
+}
+
+func rates(prev, cur sample) (uploadRate, downloadRate float64) {
+	if prev.time.IsZero() || cur.upload < prev.upload || cur.download < prev.download {
+		return 0, 0
+	}
+
+	elapsed := cur.time.Sub(prev.time).Seconds()
+	if elapsed <= 0 {
+		return 0, 0
+	}
+
+	return float64(cur.upload-prev.upload) / elapsed, float64(cur.download-prev.download) / elapsed
+}
+
+func (b *Broker) record(name string, cur sample) sample {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	cur.uploadRate, cur.downloadRate = rates(b.samples[name], cur)
+	b.samples[name] = cur
+
+	return cur
+}
+
+func (b *Broker) prune(names map[string]bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for name := range b.samples {
+		if !names[name] {
+			delete(b.samples, name)
+		}
+	}
+}
+
+func (b *Broker) sample() {
+	statuses, err := clitypes.LoadServiceStatuses(b.ctx.Home())
+	if err != nil {
+		return
+	}
+
+	names := make(map[string]bool)
+	for _, status := range statuses {
+		service, err := b.ctx.Service(status)
+		if err != nil || !service.IsUp() {
+			continue
+		}
+
+		upload, download, err := service.Transfer()
+		if err != nil {
+			continue
+		}
+
+		names[status.Name] = true
+		v := b.record(status.Name, sample{time: time.Now(), upload: upload, download: download})
+
+		if b.hasSubscribers() {
+			b.Publish(clitypes.NewSampleEvent(status.Name, upload, download, v.uploadRate, v.downloadRate))
+		}
+	}
+
+	b.prune(names)
+}
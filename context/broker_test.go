@@ -0,0 +1,74 @@
+package context_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/services"
+	"github.com/sentinel-official/cli-client/services/fake"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func TestBrokerRates(t *testing.T) {
+	var (
+		home     = t.TempDir()
+		backend  = fake.NewBackend()
+		registry = services.NewRegistry()
+	)
+
+	if err := backend.Register(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.NewServerContext().
+		WithHome(home).
+		WithRegistry(registry)
+
+	status := clitypes.NewServiceStatus().
+		WithName("default").
+		WithType(clitypes.ServiceTypeWireGuard).
+		WithIFace("wgtest0")
+	if err := status.SaveToPath(ctx.StatusFilePath("default")); err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := ctx.Service(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	broker := context.NewBroker(ctx).
+		WithInterval(50 * time.Millisecond)
+	go broker.Start()
+
+	var (
+		total    int64
+		deadline = time.Now().Add(5 * time.Second)
+	)
+
+	for time.Now().Before(deadline) {
+		total += 1000
+		if err := backend.SetTransfer("wgtest0", total, total); err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		upload, download := broker.Rates("default")
+		if upload <= 0 || download <= 0 {
+			continue
+		}
+
+		if v, w := broker.Rates("default"); v <= 0 || w <= 0 {
+			t.Fatalf("expected repeated reads to keep the rates, got %f and %f", v, w)
+		}
+
+		return
+	}
+
+	t.Fatal("expected the sampler to compute the rates")
+}
@@ -0,0 +1,441 @@
+package context
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	v2raytypes "github.com/sentinel-official/cli-client/services/v2ray/types"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+	cliutils "github.com/sentinel-official/cli-client/utils"
+)
+
+type ConnectionError struct {
+	Status int
+	Code   int
+	Err    error
+}
+
+func newConnectionError(status, code int, err error) *ConnectionError {
+	return &ConnectionError{
+		Status: status,
+		Code:   code,
+		Err:    err,
+	}
+}
+
+func (e *ConnectionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+func newLimits(req *restrequests.Connect) Limits {
+	return Limits{
+		Name:        req.Name,
+		Backend:     req.Backend,
+		Password:    req.Password,
+		From:        req.From,
+		MaxBytes:    req.MaxBytes,
+		MaxDuration: req.MaxDuration,
+		EndSession:  req.EndSession,
+		Tx:          req.Tx,
+	}
+}
+
+func newLimitStatus(req *restrequests.Connect) *clitypes.LimitStatus {
+	if req.MaxBytes == 0 && req.MaxDuration == 0 {
+		return nil
+	}
+
+	return &clitypes.LimitStatus{
+		MaxBytes:    req.MaxBytes,
+		MaxDuration: req.MaxDuration,
+		EndSession:  req.EndSession,
+	}
+}
+
+func (c ServerContext) publish(name, state string, err error) {
+	if err != nil {
+		c.broker.Publish(clitypes.NewStateEvent(name, clitypes.StateError, err))
+		return
+	}
+
+	c.broker.Publish(clitypes.NewStateEvent(name, state, nil))
+}
+
+func (c ServerContext) RollbackService(name string, service clitypes.Service) {
+	if service.IsUp() {
+		_ = service.PreDown()
+		_ = service.Down()
+	}
+
+	_ = os.Remove(c.StatusFilePath(name))
+	_ = c.RemoveConfigFile(name)
+	_ = service.PostDown()
+}
+
+func (c ServerContext) CheckRoutingScope(name string, include []wireguardtypes.IPNet) error {
+	statuses, err := clitypes.LoadServiceStatuses(c.home)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.Name == name {
+			continue
+		}
+
+		if status.IsDefaultRoute() {
+			if len(include) == 0 {
+				return fmt.Errorf("connection %s already routes the default traffic", status.Name)
+			}
+
+			continue
+		}
+
+		items, err := wireguardtypes.ParseIPNets(status.Include)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			for _, v := range include {
+				if item.Overlaps(v) {
+					return fmt.Errorf("include %s overlaps with connection %s", v.String(), status.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c ServerContext) Connect(req *restrequests.Connect) error {
+	c.Lock()
+	defer c.Unlock()
+
+	status := clitypes.NewServiceStatus()
+	if err := status.LoadFromPath(c.StatusFilePath(req.Name)); err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1003, err)
+	}
+
+	if status.IFace != "" {
+		if service, err := c.Service(status); err == nil && service.IsUp() {
+			return newConnectionError(
+				http.StatusBadRequest, 1004,
+				fmt.Errorf("connection %s is already running on interface %s", req.Name, status.IFace),
+			)
+		}
+	}
+
+	c.supervisor.Unwatch(req.Name)
+	c.limiter.Unwatch(req.Name)
+
+	c.broker.Publish(clitypes.NewStateEvent(req.Name, clitypes.StateConnecting, nil))
+
+	var err error
+	if req.Type == clitypes.ServiceTypeV2Ray {
+		err = c.connectV2Ray(req)
+	} else {
+		err = c.connectWireGuard(req, status)
+	}
+
+	c.publish(req.Name, clitypes.StateUp, err)
+	return err
+}
+
+func (c ServerContext) connectWireGuard(req *restrequests.Connect, status *clitypes.ServiceStatus) error {
+	include, err := wireguardtypes.ParseIPNets(req.Include)
+	if err != nil {
+		return newConnectionError(http.StatusBadRequest, 1005, err)
+	}
+
+	exclude, err := wireguardtypes.ParseIPNets(req.Exclude)
+	if err != nil {
+		return newConnectionError(http.StatusBadRequest, 1006, err)
+	}
+
+	if req.Mode == clitypes.ServiceModeTunnel {
+		if err := c.CheckRoutingScope(req.Name, include); err != nil {
+			return newConnectionError(http.StatusBadRequest, 1014, err)
+		}
+	}
+
+	iFace := status.IFace
+	if req.Mode == clitypes.ServiceModeProxy {
+		iFace = fmt.Sprintf("%s-%s", wireguardtypes.UserspacePrefix, req.Name)
+	} else if req.IFace != "" {
+		if err := c.CheckInterface(req.Name, req.IFace); err != nil {
+			return newConnectionError(http.StatusBadRequest, 1019, err)
+		}
+
+		iFace = req.IFace
+	} else if iFace == "" || status.IsV2Ray() || status.IsProxy() {
+		iFace, err = c.NextInterface()
+		if err != nil {
+			return newConnectionError(http.StatusInternalServerError, 1019, err)
+		}
+	}
+
+	backend := req.ServiceBackend
+	if backend == "" {
+		backend = c.registry.Default(req.Type)
+		switch req.Mode {
+		case clitypes.ServiceModeProxy:
+			backend = clitypes.ServiceBackendUserspace
+		case clitypes.ServiceModeNamespace:
+			backend = clitypes.ServiceBackendNamespace
+		}
+	}
+
+	if !c.registry.Has(req.Type, backend) {
+		return newConnectionError(
+			http.StatusBadRequest, 1017,
+			fmt.Errorf("service backend %s is not available", backend),
+		)
+	}
+	if (backend == clitypes.ServiceBackendUserspace) != (req.Mode == clitypes.ServiceModeProxy) ||
+		(backend == clitypes.ServiceBackendNamespace) != (req.Mode == clitypes.ServiceModeNamespace) {
+		return newConnectionError(
+			http.StatusBadRequest, 1017,
+			fmt.Errorf("service backend %s is not supported by mode %s", backend, req.Mode),
+		)
+	}
+
+	listenPort, err := cliutils.GetFreeUDPPort()
+	if err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1007, err)
+	}
+
+	cfg := wireguardtypes.NewConfigFromInfo(
+		iFace,
+		req.Info,
+		wireguardtypes.NewKey(req.Keys[0]),
+		listenPort,
+		!req.NoNodeResolver,
+		req.Resolvers,
+		include,
+		exclude,
+	)
+
+	cfg.Interface.MTU = req.MTU
+	cfg.Interface.DNSSearch = req.DNSSearch
+	cfg.Peers[0].PersistentKeepalive = req.PersistentKeepalive
+
+	var namespace string
+	if req.Mode == clitypes.ServiceModeNamespace {
+		namespace = fmt.Sprintf("%s-%s", wireguardtypes.NamespacePrefix, req.Name)
+	}
+
+	status = clitypes.NewServiceStatus().
+		WithType(req.Type).
+		WithBackend(backend).
+		WithID(req.ID).
+		WithMode(req.Mode).
+		WithIFace(cfg.Name).
+		WithProxy(req.ProxyListen).
+		WithNamespace(namespace).
+		WithEndpoint(cfg.Peers[0].Endpoint.String()).
+		WithInclude(req.Include).
+		WithExclude(req.Exclude).
+		WithKillSwitch(req.KillSwitch).
+		WithNode(req.To).
+		WithSubscription(req.Subscription).
+		WithFrom(req.From).
+		WithLimits(newLimitStatus(req)).
+		WithStartAt(time.Now())
+
+	service, err := c.NewService(status, cfg)
+	if err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1018, err)
+	}
+
+	if err := c.up(req.Name, status, service); err != nil {
+		return err
+	}
+
+	if err := cfg.SaveToPath(c.ConfigFilePath(req.Name)); err != nil {
+		c.RollbackService(req.Name, service)
+		return newConnectionError(http.StatusInternalServerError, 1016, err)
+	}
+
+	c.supervisor.Watch(
+		Connection{
+			Name:                req.Name,
+			Backend:             req.Backend,
+			Password:            req.Password,
+			ID:                  req.ID,
+			Subscription:        req.Subscription,
+			From:                req.From,
+			To:                  req.To,
+			Mode:                req.Mode,
+			ProxyListen:         req.ProxyListen,
+			ServiceBackend:      backend,
+			Namespace:           namespace,
+			IFace:               cfg.Name,
+			Resolvers:           req.Resolvers,
+			NoNodeResolver:      req.NoNodeResolver,
+			Include:             req.Include,
+			Exclude:             req.Exclude,
+			KillSwitch:          req.KillSwitch,
+			MTU:                 req.MTU,
+			PersistentKeepalive: req.PersistentKeepalive,
+			DNSSearch:           req.DNSSearch,
+			Tx:                  req.Tx,
+		},
+	)
+	c.limiter.Watch(newLimits(req))
+
+	return nil
+}
+
+func (c ServerContext) connectV2Ray(req *restrequests.Connect) error {
+	uid, err := v2raytypes.NewUUIDFromBytes(req.Keys[0])
+	if err != nil {
+		return newConnectionError(http.StatusBadRequest, 1015, err)
+	}
+
+	apiListen, err := cliutils.GetFreeTCPPort()
+	if err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1007, err)
+	}
+
+	listen, err := cliutils.GetFreeTCPPort()
+	if err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1007, err)
+	}
+
+	cfg, err := v2raytypes.NewConfigFromInfo(
+		fmt.Sprintf("%s-%s", v2raytypes.InstancePrefix, req.Name),
+		req.Host,
+		req.Info,
+		uid,
+		apiListen,
+		listen,
+	)
+	if err != nil {
+		return newConnectionError(http.StatusBadRequest, 1015, err)
+	}
+
+	status := clitypes.NewServiceStatus().
+		WithType(req.Type).
+		WithBackend(clitypes.ServiceBackendV2Ray).
+		WithID(req.ID).
+		WithIFace(cfg.Name).
+		WithProxy(cfg.ListenAddress()).
+		WithNode(req.To).
+		WithSubscription(req.Subscription).
+		WithFrom(req.From).
+		WithLimits(newLimitStatus(req)).
+		WithStartAt(time.Now())
+
+	service, err := c.NewService(status, cfg)
+	if err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1018, err)
+	}
+
+	if err := c.up(req.Name, status, service); err != nil {
+		return err
+	}
+
+	c.limiter.Watch(newLimits(req))
+
+	return nil
+}
+
+func (c ServerContext) up(name string, status *clitypes.ServiceStatus, service clitypes.Service) error {
+	if err := status.SaveToPath(c.StatusFilePath(name)); err != nil {
+		c.RollbackService(name, service)
+		return newConnectionError(http.StatusInternalServerError, 1008, err)
+	}
+
+	if err := service.PreUp(); err != nil {
+		c.RollbackService(name, service)
+		return newConnectionError(http.StatusInternalServerError, 1009, err)
+	}
+	if err := service.Up(); err != nil {
+		c.RollbackService(name, service)
+		return newConnectionError(http.StatusInternalServerError, 1010, err)
+	}
+	if err := service.PostUp(); err != nil {
+		c.RollbackService(name, service)
+
+		code := 1011
+		if IsKillSwitchError(err) {
+			code = 1013
+		}
+
+		return newConnectionError(http.StatusInternalServerError, code, err)
+	}
+
+	return nil
+}
+
+func (c ServerContext) Disconnect(name string) (err error) {
+	c.Lock()
+	defer c.Unlock()
+
+	defer func() {
+		c.publish(name, clitypes.StateDown, err)
+	}()
+
+	var (
+		status         = clitypes.NewServiceStatus()
+		statusFilePath = c.StatusFilePath(name)
+	)
+
+	if err := status.LoadFromPath(statusFilePath); err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1001, err)
+	}
+
+	record := c.NewHistoryRecord(name, status, clitypes.HistoryReasonDisconnect)
+
+	c.supervisor.Unwatch(name)
+	c.limiter.Unwatch(name)
+
+	if status.IFace != "" {
+		service, err := c.Service(status)
+		if err != nil {
+			return newConnectionError(http.StatusInternalServerError, 1011, err)
+		}
+
+		if service.IsUp() {
+			if err := service.PreDown(); err != nil {
+				return newConnectionError(http.StatusInternalServerError, 1002, err)
+			}
+			if err := service.Down(); err != nil {
+				return newConnectionError(http.StatusInternalServerError, 1003, err)
+			}
+		}
+
+		if err := service.PostDown(); err != nil {
+			code := 1004
+			if IsKillSwitchError(err) {
+				code = 1006
+			}
+
+			return newConnectionError(http.StatusInternalServerError, code, err)
+		}
+	}
+
+	if err := os.Remove(statusFilePath); err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1005, err)
+	}
+	if err := c.RemoveConfigFile(name); err != nil {
+		return newConnectionError(http.StatusInternalServerError, 1010, err)
+	}
+
+	if status.IFace != "" {
+		if err := c.AppendHistoryRecord(record); err != nil {
+			return newConnectionError(http.StatusInternalServerError, 1009, err)
+		}
+	}
+
+	return nil
+}
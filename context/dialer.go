@@ -0,0 +1,123 @@
+package context
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	hubtypes "github.com/sentinel-official/hub/types"
+	sessiontypes "github.com/sentinel-official/hub/x/session/types"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+	"github.com/sentinel-official/cli-client/x/node/handshake"
+	clinodetypes "github.com/sentinel-official/cli-client/x/node/types"
+)
+
+type Dialer interface {
+	RemoteURL(tc *TxContext, node string) (string, error)
+	NodeInfo(remoteURL string) (clinodetypes.NodeInfo, error)
+	StartSession(tc *TxContext, password string, from sdk.AccAddress, subscription uint64, node string, rating uint64) (uint64, error)
+	EndSession(tc *TxContext, password string, from sdk.AccAddress, id uint64) error
+	Handshake(tc *TxContext, password string, from sdk.AccAddress, remoteURL string, id uint64, peerKey []byte, infoSize int) ([]byte, error)
+}
+
+type chainDialer struct{}
+
+func (chainDialer) RemoteURL(tc *TxContext, node string) (string, error) {
+	nodeAddr, err := hubtypes.NodeAddressFromBech32(node)
+	if err != nil {
+		return "", err
+	}
+
+	v, err := tc.QueryNode(nodeAddr)
+	if err != nil {
+		return "", err
+	}
+
+	return v.RemoteURL, nil
+}
+
+func (chainDialer) NodeInfo(remoteURL string) (clinodetypes.NodeInfo, error) {
+	return clinodetypes.FetchNodeInfo(remoteURL, clitypes.Timeout)
+}
+
+func (chainDialer) StartSession(tc *TxContext, password string, from sdk.AccAddress, subscription uint64, node string, rating uint64) (uint64, error) {
+	nodeAddr, err := hubtypes.NodeAddressFromBech32(node)
+	if err != nil {
+		return 0, err
+	}
+
+	session, err := tc.QueryActiveSession(from)
+	if err != nil {
+		return 0, err
+	}
+
+	var messages []sdk.Msg
+	if session != nil {
+		messages = append(
+			messages,
+			sessiontypes.NewMsgEndRequest(
+				from,
+				session.Id,
+				rating,
+			),
+		)
+	}
+
+	messages = append(
+		messages,
+		sessiontypes.NewMsgStartRequest(
+			from,
+			subscription,
+			nodeAddr,
+		),
+	)
+
+	res, err := tc.SignMessagesAndBroadcastTx(password, messages...)
+	if err != nil {
+		return 0, err
+	}
+	if res.Code != 0 {
+		return 0, errors.New(res.RawLog)
+	}
+
+	session, err = tc.QueryActiveSession(from)
+	if err != nil {
+		return 0, err
+	}
+	if session == nil {
+		return 0, fmt.Errorf("active session does not exist for subscription %d", subscription)
+	}
+
+	return session.Id, nil
+}
+
+func (chainDialer) EndSession(tc *TxContext, password string, from sdk.AccAddress, id uint64) error {
+	res, err := tc.SignMessagesAndBroadcastTx(password, sessiontypes.NewMsgEndRequest(from, id, 0))
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return errors.New(res.RawLog)
+	}
+
+	return nil
+}
+
+func (chainDialer) Handshake(tc *TxContext, password string, from sdk.AccAddress, remoteURL string, id uint64, peerKey []byte, infoSize int) ([]byte, error) {
+	signMsgRes, err := tc.SignMessage(password, tc.From, sdk.Uint64ToBigEndian(id))
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(signMsgRes.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return handshake.NewClient().
+		WithHTTPClient(tc.KeyringContext.Client).
+		WithInfoSize(infoSize).
+		Handshake(remoteURL, from.String(), id, peerKey, signature)
+}
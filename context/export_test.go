@@ -0,0 +1,9 @@
+package context
+
+import (
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+)
+
+func (s *Supervisor) Up(conn Connection, info []byte, key *wireguardtypes.Key) error {
+	return s.up(&conn, info, key)
+}
@@ -8,8 +8,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"path/filepath"
 
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/go-kit/kit/transport/http/jsonrpc"
 	"github.com/spf13/cobra"
@@ -26,6 +26,7 @@ type KeyringContext struct {
 	Backend string
 	Home    string
 	URL     string
+	Keyring keyring.Keyring
 }
 
 func NewKeyringContextFromCmd(cmd *cobra.Command) (ctx KeyringContext, err error) {
@@ -44,7 +45,7 @@ func NewKeyringContextFromCmd(cmd *cobra.Command) (ctx KeyringContext, err error
 		return ctx, err
 	}
 
-	ctx.URL, err = cliutils.ReadLineFromFile(filepath.Join(ctx.Home, "url.txt"))
+	ctx.Client, ctx.URL, err = newClient(ctx.Client, ctx.Home)
 	if err != nil {
 		return ctx, err
 	}
@@ -72,6 +73,11 @@ func (c KeyringContext) WithURL(v string) KeyringContext {
 	return c
 }
 
+func (c KeyringContext) WithKeyring(v keyring.Keyring) KeyringContext {
+	c.Keyring = v
+	return c
+}
+
 func (c *KeyringContext) GetPasswordAndAddress(r *bufio.Reader, name string) (string, sdk.AccAddress, error) {
 	password, err := cliutils.GetPassword(c.Backend, r)
 	if err != nil {
@@ -145,6 +151,16 @@ func (c *KeyringContext) GetKeys(password string) (clitypes.Keys, error) {
 }
 
 func (c *KeyringContext) GetKey(password, name string) (*clitypes.Key, error) {
+	if c.Keyring != nil {
+		key, err := c.Keyring.Key(name)
+		if err != nil {
+			return nil, err
+		}
+
+		res := clitypes.NewKeyFromRaw(key)
+		return &res, nil
+	}
+
 	path, err := url.JoinPath(c.URL, restroutes.GetKey)
 	if err != nil {
 		return nil, err
@@ -273,6 +289,18 @@ func (c *KeyringContext) DeleteKey(password, name string) error {
 }
 
 func (c *KeyringContext) SignMessage(password, name string, message []byte) (*restresponses.SignMessage, error) {
+	if c.Keyring != nil {
+		signature, pubKey, err := c.Keyring.Sign(name, message)
+		if err != nil {
+			return nil, err
+		}
+
+		return &restresponses.SignMessage{
+			PubKey:    base64.StdEncoding.EncodeToString(pubKey.Bytes()),
+			Signature: base64.StdEncoding.EncodeToString(signature),
+		}, nil
+	}
+
 	path, err := url.JoinPath(c.URL, restroutes.SignMessage)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,78 @@
+package context
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/sentinel-official/cli-client/services/killswitch"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+type KillSwitchError struct {
+	Err error
+}
+
+func (e *KillSwitchError) Error() string {
+	return fmt.Sprintf("failed to update the kill switch: %s", e.Err)
+}
+
+func IsKillSwitchError(err error) bool {
+	var e *KillSwitchError
+	return errors.As(err, &e)
+}
+
+type KillSwitch interface {
+	Enable(iFaces []string, endpoints []wireguardtypes.Endpoint, allowed []wireguardtypes.IPNet) error
+	Disable() error
+}
+
+type systemKillSwitch struct{}
+
+func (systemKillSwitch) Enable(iFaces []string, endpoints []wireguardtypes.Endpoint, allowed []wireguardtypes.IPNet) error {
+	return killswitch.NewKillSwitch().
+		WithInterfaces(iFaces...).
+		WithEndpoints(endpoints...).
+		WithAllowed(allowed).
+		Enable()
+}
+
+func (systemKillSwitch) Disable() error {
+	return killswitch.NewKillSwitch().Disable()
+}
+
+type killSwitchService struct {
+	clitypes.Service
+	ctx   ServerContext
+	iFace string
+	keep  bool
+}
+
+func (s *killSwitchService) PostUp() error {
+	if err := s.Service.PostUp(); err != nil {
+		return err
+	}
+
+	if err := s.ctx.updateKillSwitch("", false); err != nil {
+		_ = s.Service.PreDown()
+		_ = s.Service.Down()
+		_ = s.Service.PostDown()
+		_ = s.ctx.updateKillSwitch(s.iFace, s.keep)
+
+		return &KillSwitchError{Err: err}
+	}
+
+	return nil
+}
+
+func (s *killSwitchService) PostDown() error {
+	if err := s.Service.PostDown(); err != nil {
+		return err
+	}
+	if err := s.ctx.updateKillSwitch(s.iFace, s.keep); err != nil {
+		return &KillSwitchError{Err: err}
+	}
+
+	return nil
+}
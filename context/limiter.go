@@ -0,0 +1,292 @@
+package context
+
+import (
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	sessiontypes "github.com/sentinel-official/hub/x/session/types"
+
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+type Limits struct {
+	Name        string
+	Backend     string
+	Password    string
+	From        string
+	MaxBytes    int64
+	MaxDuration time.Duration
+	EndSession  bool
+	Tx          *restrequests.Tx
+	StartAt     time.Time
+}
+
+func (l Limits) IsEmpty() bool {
+	return l.MaxBytes == 0 && l.MaxDuration == 0
+}
+
+type limit struct {
+	limits  Limits
+	startAt time.Time
+	offset  int64
+	last    int64
+}
+
+func (l *limit) bytes() int64 {
+	return l.offset + l.last
+}
+
+func (l *limit) duration() time.Duration {
+	return time.Since(l.startAt)
+}
+
+func (l *limit) isReached() bool {
+	if l.limits.MaxBytes > 0 && l.bytes() >= l.limits.MaxBytes {
+		return true
+	}
+	if l.limits.MaxDuration > 0 && l.duration() >= l.limits.MaxDuration {
+		return true
+	}
+
+	return false
+}
+
+type Limiter struct {
+	ctx      ServerContext
+	interval time.Duration
+
+	mutex  sync.Mutex
+	limits map[string]*limit
+}
+
+func NewLimiter(ctx ServerContext) *Limiter {
+	return &Limiter{
+		ctx:    ctx,
+		limits: make(map[string]*limit),
+	}
+}
+
+func (l *Limiter) WithInterval(v time.Duration) *Limiter { l.interval = v; return l }
+
+func (l *Limiter) Watch(v Limits) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if v.IsEmpty() {
+		delete(l.limits, v.Name)
+		return
+	}
+
+	startAt := v.StartAt
+	if startAt.IsZero() {
+		startAt = time.Now()
+	}
+
+	l.limits[v.Name] = &limit{
+		limits:  v,
+		startAt: startAt,
+	}
+}
+
+func (l *Limiter) Unwatch(name string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.limits, name)
+}
+
+func (l *Limiter) Status(name string) *clitypes.LimitStatus {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	v, ok := l.limits[name]
+	if !ok {
+		return nil
+	}
+
+	return &clitypes.LimitStatus{
+		MaxBytes:    v.limits.MaxBytes,
+		Bytes:       v.bytes(),
+		MaxDuration: v.limits.MaxDuration,
+		Duration:    v.duration(),
+		EndSession:  v.limits.EndSession,
+	}
+}
+
+func (l *Limiter) Start() {
+	if l.interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, name := range l.names() {
+			if err := l.check(name); err != nil {
+				log.Printf("Failed to enforce the limits of connection %s: %s", name, err)
+			}
+		}
+	}
+}
+
+func (l *Limiter) names() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	names := make([]string, 0, len(l.limits))
+	for name := range l.limits {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (l *Limiter) update(name string, total int64) (limits Limits, reached, ok bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	v, ok := l.limits[name]
+	if !ok {
+		return limits, false, false
+	}
+
+	if total < v.last {
+		v.offset += v.last
+	}
+
+	v.last = total
+	return v.limits, v.isReached(), true
+}
+
+func (l *Limiter) check(name string) error {
+	limits, id, err := l.enforce(name)
+	if err != nil {
+		return err
+	}
+	if id == 0 || !limits.EndSession {
+		return nil
+	}
+
+	return l.endSession(limits, id)
+}
+
+func (l *Limiter) enforce(name string) (limits Limits, id uint64, err error) {
+	l.ctx.Lock()
+	defer l.ctx.Unlock()
+
+	status := clitypes.NewServiceStatus()
+	if err := status.LoadFromPath(l.ctx.StatusFilePath(name)); err != nil {
+		return limits, 0, err
+	}
+	if status.IFace == "" {
+		l.Unwatch(name)
+		return limits, 0, nil
+	}
+
+	service, err := l.ctx.Service(status)
+	if err != nil {
+		return limits, 0, err
+	}
+
+	var total int64
+	if service.IsUp() {
+		upload, download, err := service.Transfer()
+		if err != nil {
+			return limits, 0, err
+		}
+
+		total = upload + download
+	}
+
+	limits, reached, ok := l.update(name, total)
+	if !ok || !reached {
+		return limits, 0, nil
+	}
+
+	log.Printf("Connection %s reached its limits, disconnecting", name)
+
+	l.Unwatch(name)
+	if err := l.disconnect(name, status); err != nil {
+		l.ctx.Broker().Publish(clitypes.NewStateEvent(name, clitypes.StateError, err))
+		return limits, 0, err
+	}
+
+	l.ctx.Broker().Publish(clitypes.NewStateEvent(name, clitypes.StateDown, nil))
+
+	return limits, status.ID, nil
+}
+
+func (l *Limiter) disconnect(name string, status *clitypes.ServiceStatus) error {
+	record := l.ctx.NewHistoryRecord(name, status, clitypes.HistoryReasonLimit)
+	if supervisor := l.ctx.Supervisor(); supervisor != nil {
+		supervisor.Unwatch(name)
+	}
+
+	service, err := l.ctx.Service(status)
+	if err != nil {
+		return err
+	}
+
+	if service.IsUp() {
+		if err := service.PreDown(); err != nil {
+			return err
+		}
+		if err := service.Down(); err != nil {
+			return err
+		}
+	}
+
+	if err := service.PostDown(); err != nil {
+		return err
+	}
+
+	if err := os.Remove(l.ctx.StatusFilePath(name)); err != nil {
+		return err
+	}
+	if err := l.ctx.RemoveConfigFile(name); err != nil {
+		return err
+	}
+
+	return l.ctx.AppendHistoryRecord(record)
+}
+
+func (l *Limiter) endSession(limits Limits, id uint64) error {
+	if limits.Tx == nil {
+		return errors.New("tx settings are not provided")
+	}
+
+	accAddr, err := sdk.AccAddressFromBech32(limits.From)
+	if err != nil {
+		return err
+	}
+
+	tc, err := l.ctx.NewTxContext(limits.Tx, limits.Backend, limits.Password, limits.From)
+	if err != nil {
+		return err
+	}
+
+	res, err := tc.SignMessagesAndBroadcastTx(
+		limits.Password,
+		sessiontypes.NewMsgEndRequest(
+			accAddr,
+			id,
+			0,
+		),
+	)
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return errors.New(res.RawLog)
+	}
+
+	return nil
+}
@@ -24,20 +24,24 @@ type QueryContext struct {
 	client.Context
 }
 
-func NewQueryContextFromCmd(cmd *cobra.Command) (ctx QueryContext, err error) {
-	ctx.Context = client.GetClientContextFromCmd(cmd)
+func NewQueryContext(v client.Context, rpcAddress string) (ctx QueryContext, err error) {
+	ctx.Context = v.WithNodeURI(rpcAddress)
 
-	ctx.NodeURI, err = cmd.Flags().GetString(clitypes.FlagRPCAddress)
+	ctx.Client, err = rpchttp.New(ctx.NodeURI, "/websocket")
 	if err != nil {
 		return ctx, err
 	}
 
-	ctx.Client, err = rpchttp.New(ctx.NodeURI, "/websocket")
+	return ctx, nil
+}
+
+func NewQueryContextFromCmd(cmd *cobra.Command) (ctx QueryContext, err error) {
+	rpcAddress, err := cmd.Flags().GetString(clitypes.FlagRPCAddress)
 	if err != nil {
 		return ctx, err
 	}
 
-	return ctx, nil
+	return NewQueryContext(client.GetClientContextFromCmd(cmd), rpcAddress)
 }
 
 func (c QueryContext) WithContext(v client.Context) QueryContext {
@@ -160,6 +164,53 @@ func (c *QueryContext) QueryNodesForProvider(provAddr hubtypes.ProvAddress, stat
 	return resp.Nodes, nil
 }
 
+func (c *QueryContext) QueryNodesForPlan(id uint64, pagination *query.PageRequest) (nodetypes.Nodes, error) {
+	var (
+		qsc       = plantypes.NewQueryServiceClient(c)
+		resp, err = qsc.QueryNodesForPlan(
+			context.Background(),
+			plantypes.NewQueryNodesForPlanRequest(
+				id,
+				pagination,
+			),
+		)
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Nodes, nil
+}
+
+func (c *QueryContext) QueryAllNodesForPlan(id uint64) (nodetypes.Nodes, error) {
+	var (
+		items nodetypes.Nodes
+		qsc   = plantypes.NewQueryServiceClient(c)
+		key   []byte
+	)
+
+	for {
+		resp, err := qsc.QueryNodesForPlan(
+			context.Background(),
+			plantypes.NewQueryNodesForPlanRequest(
+				id,
+				&query.PageRequest{Key: key, Limit: query.MaxLimit},
+			),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, resp.Nodes...)
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			return items, nil
+		}
+
+		key = resp.Pagination.NextKey
+	}
+}
+
 func (c *QueryContext) QueryPlan(id uint64) (*plantypes.Plan, error) {
 	var (
 		qsc       = plantypes.NewQueryServiceClient(c)
@@ -0,0 +1,428 @@
+package context
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	hubtypes "github.com/sentinel-official/hub/types"
+
+	v2raytypes "github.com/sentinel-official/cli-client/services/v2ray/types"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func (c ServerContext) migrateLegacyStatus() error {
+	legacyFilePath := filepath.Join(c.home, clitypes.LegacyStatusFile)
+	if _, err := os.Stat(legacyFilePath); err != nil {
+		return nil
+	}
+
+	statusFilePath := c.StatusFilePath(clitypes.DefaultConnection)
+	if _, err := os.Stat(statusFilePath); err == nil {
+		log.Printf("Skipping the legacy status file %s, connection %s already exists", legacyFilePath, clitypes.DefaultConnection)
+		return nil
+	}
+
+	status := clitypes.NewServiceStatus()
+	if err := status.LoadFromPath(legacyFilePath); err != nil {
+		return err
+	}
+
+	if status.IFace != "" {
+		if status.Type == 0 {
+			status.Type = clitypes.ServiceTypeWireGuard
+		}
+		if status.Type == clitypes.ServiceTypeWireGuard && status.Backend == "" {
+			status.Backend = clitypes.ServiceBackendWgQuick
+		}
+
+		data, err := os.ReadFile(filepath.Join(c.home, fmt.Sprintf("%s.conf", status.IFace)))
+		if err == nil {
+			configFilePath := c.ConfigFilePath(clitypes.DefaultConnection)
+			if err := os.MkdirAll(filepath.Dir(configFilePath), 0700); err != nil {
+				return err
+			}
+			if err := os.WriteFile(configFilePath, data, 0600); err != nil {
+				return err
+			}
+		}
+
+		if err := status.SaveToPath(statusFilePath); err != nil {
+			return err
+		}
+
+		log.Printf("Migrated the legacy status of interface %s to connection %s", status.IFace, clitypes.DefaultConnection)
+	}
+
+	return os.Remove(legacyFilePath)
+}
+
+func (c ServerContext) Reconcile(policy string) error {
+	if err := c.migrateLegacyStatus(); err != nil {
+		return err
+	}
+	if policy == clitypes.OnStartIgnore {
+		return nil
+	}
+
+	statuses, err := clitypes.LoadServiceStatuses(c.home)
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	for _, status := range statuses {
+		if policy == clitypes.OnStartRestore {
+			err := c.restore(status)
+			if err == nil {
+				log.Printf("Restored connection %s on interface %s", status.Name, status.IFace)
+
+				used[status.IFace] = true
+				continue
+			}
+
+			log.Printf("Failed to restore connection %s: %s", status.Name, err)
+		}
+
+		if err := c.cleanup(status); err != nil {
+			return err
+		}
+
+		log.Printf("Cleaned up connection %s on interface %s", status.Name, status.IFace)
+	}
+
+	if err := c.removeOrphans(statuses, used); err != nil {
+		log.Printf("Failed to remove the orphaned files: %s", err)
+	}
+
+	return nil
+}
+
+func (c ServerContext) restore(status *clitypes.ServiceStatus) error {
+	verified, err := c.verifySession(status)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		log.Printf("Session %d of connection %s cannot be verified without --rpc-address", status.ID, status.Name)
+	}
+
+	if status.IsV2Ray() {
+		if err := c.restoreV2Ray(status); err != nil {
+			return err
+		}
+
+		c.watch(status, nil)
+		return nil
+	}
+
+	service, err := c.Service(status)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(c.ConfigFilePath(status.Name))
+	if err != nil {
+		if service.IsUp() {
+			c.watch(status, nil)
+			return nil
+		}
+
+		return err
+	}
+
+	cfg, err := wireguardtypes.ParseWgQuick(data)
+	if err != nil {
+		return err
+	}
+
+	cfg.Name = status.IFace
+
+	service, err = c.NewService(status, cfg)
+	if err != nil {
+		return err
+	}
+
+	if !service.IsUp() {
+		if err := service.PreUp(); err != nil {
+			return err
+		}
+		if err := service.Up(); err != nil {
+			return err
+		}
+		if err := service.PostUp(); err != nil {
+			return err
+		}
+	}
+
+	c.watch(status, cfg)
+	return nil
+}
+
+func (c ServerContext) restoreV2Ray(status *clitypes.ServiceStatus) error {
+	service, err := c.Service(status)
+	if err != nil {
+		return err
+	}
+	if service.IsUp() {
+		return nil
+	}
+
+	cfg := &v2raytypes.Config{
+		Name: status.IFace,
+	}
+
+	if _, err := os.Stat(cfg.FilePath(c.home)); err != nil {
+		return err
+	}
+	if err := service.Up(); err != nil {
+		return err
+	}
+
+	return service.PostUp()
+}
+
+func (c ServerContext) verifySession(status *clitypes.ServiceStatus) (bool, error) {
+	if status.ID == 0 {
+		return true, nil
+	}
+
+	qc, err := c.sessionQuery()
+	if err != nil {
+		return false, err
+	}
+	if qc == nil {
+		return false, nil
+	}
+
+	session, err := qc.QuerySession(status.ID)
+	if err != nil {
+		return false, err
+	}
+	if !session.Status.Equal(hubtypes.StatusActive) {
+		return false, fmt.Errorf("session %d is %s", status.ID, session.Status)
+	}
+
+	return true, nil
+}
+
+func (c ServerContext) sessionQuery() (*QueryContext, error) {
+	if c.query != nil {
+		return c.query, nil
+	}
+	if c.signer == nil || c.signer.Tx == nil || c.signer.Tx.RPCAddress == "" {
+		return nil, nil
+	}
+
+	qc, err := NewQueryContext(c.client, c.signer.Tx.RPCAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &qc, nil
+}
+
+func (c ServerContext) watch(status *clitypes.ServiceStatus, cfg *wireguardtypes.Config) {
+	var signer Signer
+	if c.signer != nil && c.signer.From == status.From {
+		signer = *c.signer
+	}
+
+	if c.supervisor != nil && !status.IsV2Ray() {
+		conn := Connection{
+			Name:           status.Name,
+			Backend:        signer.Backend,
+			Password:       signer.Password,
+			ID:             status.ID,
+			Subscription:   status.Subscription,
+			From:           status.From,
+			To:             status.Node,
+			ServiceBackend: status.Backend,
+			Mode:           status.Mode,
+			ProxyListen:    status.Proxy,
+			Namespace:      status.Namespace,
+			IFace:          status.IFace,
+			Include:        status.Include,
+			Exclude:        status.Exclude,
+			KillSwitch:     status.KillSwitch,
+			Tx:             signer.Tx,
+		}
+
+		if cfg != nil {
+			conn.MTU = cfg.Interface.MTU
+			conn.DNSSearch = cfg.Interface.DNSSearch
+			conn.Resolvers = cfg.Interface.DNS
+			if len(conn.Resolvers) > 0 && conn.Resolvers[0].Equal(wireguardtypes.NodeResolver.IP) {
+				conn.Resolvers = conn.Resolvers[1:]
+			} else {
+				conn.NoNodeResolver = true
+			}
+			if len(cfg.Peers) > 0 {
+				conn.PersistentKeepalive = cfg.Peers[0].PersistentKeepalive
+			}
+		}
+
+		c.supervisor.Watch(conn)
+	}
+
+	if c.limiter != nil && status.Limits != nil {
+		c.limiter.Watch(
+			Limits{
+				Name:        status.Name,
+				Backend:     signer.Backend,
+				Password:    signer.Password,
+				From:        status.From,
+				MaxBytes:    status.Limits.MaxBytes,
+				MaxDuration: status.Limits.MaxDuration,
+				EndSession:  status.Limits.EndSession,
+				Tx:          signer.Tx,
+				StartAt:     status.StartAt,
+			},
+		)
+	}
+}
+
+func (c ServerContext) cleanup(status *clitypes.ServiceStatus) error {
+	return c.teardown(status, clitypes.HistoryReasonCleanup)
+}
+
+func (c ServerContext) teardown(status *clitypes.ServiceStatus, reason string) error {
+	record := c.NewHistoryRecord(status.Name, status, reason)
+
+	service, err := c.Service(status)
+	if err != nil {
+		return err
+	}
+
+	if service.IsUp() {
+		if err := service.PreDown(); err != nil {
+			return err
+		}
+		if err := service.Down(); err != nil {
+			return err
+		}
+	}
+
+	if err := service.PostDown(); err != nil {
+		return err
+	}
+	if err := os.Remove(c.StatusFilePath(status.Name)); err != nil {
+		return err
+	}
+	if err := c.RemoveConfigFile(status.Name); err != nil {
+		return err
+	}
+
+	if status.ID == 0 {
+		return nil
+	}
+
+	return c.AppendHistoryRecord(record)
+}
+
+func (c ServerContext) removeOrphans(statuses []*clitypes.ServiceStatus, used map[string]bool) error {
+	var (
+		names    = make(map[string]bool)
+		recorded = make(map[string]bool)
+	)
+
+	for _, status := range statuses {
+		recorded[status.IFace] = true
+		if used[status.IFace] {
+			names[status.Name] = true
+		}
+	}
+
+	legacy := clitypes.NewServiceStatus()
+	if err := legacy.LoadFromPath(filepath.Join(c.home, clitypes.LegacyStatusFile)); err != nil {
+		return err
+	}
+	if legacy.IFace != "" {
+		recorded[legacy.IFace] = true
+	}
+
+	entries, err := os.ReadDir(filepath.Join(c.home, clitypes.ConfigDirname))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".conf")
+		if entry.IsDir() || name == entry.Name() || names[name] {
+			continue
+		}
+
+		log.Printf("Removing the orphaned config of connection %s", name)
+		if err := c.RemoveConfigFile(name); err != nil {
+			log.Printf("Failed to remove the orphaned config of connection %s: %s", name, err)
+		}
+	}
+
+	entries, err = os.ReadDir(c.home)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		var status *clitypes.ServiceStatus
+		if name := strings.TrimSuffix(entry.Name(), ".conf"); name != entry.Name() {
+			if used[name] || !recorded[name] {
+				continue
+			}
+
+			status = clitypes.NewServiceStatus().
+				WithType(clitypes.ServiceTypeWireGuard).
+				WithBackend(clitypes.ServiceBackendWgQuick).
+				WithIFace(name)
+		} else if name := strings.TrimSuffix(entry.Name(), ".json"); name != entry.Name() {
+			if used[name] || !strings.HasPrefix(name, fmt.Sprintf("%s-", v2raytypes.InstancePrefix)) {
+				continue
+			}
+
+			status = clitypes.NewServiceStatus().
+				WithType(clitypes.ServiceTypeV2Ray).
+				WithBackend(clitypes.ServiceBackendV2Ray).
+				WithIFace(name)
+		} else {
+			continue
+		}
+
+		log.Printf("Removing the orphaned file %s", entry.Name())
+		if err := c.removeOrphan(status, filepath.Join(c.home, entry.Name())); err != nil {
+			log.Printf("Failed to remove the orphaned file %s: %s", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func (c ServerContext) removeOrphan(status *clitypes.ServiceStatus, path string) error {
+	service, err := c.Service(status)
+	if err != nil {
+		return err
+	}
+
+	if service.IsUp() {
+		if err := service.PreDown(); err != nil {
+			return err
+		}
+		if err := service.Down(); err != nil {
+			return err
+		}
+	}
+	if err := service.PostDown(); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package context_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/services"
+	"github.com/sentinel-official/cli-client/services/fake"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func TestReconcileMigratesLegacyStatus(t *testing.T) {
+	home := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(home, clitypes.LegacyStatusFile), []byte(`{"id":7,"iface":"wg99","kill_switch":true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "wg99.conf"), []byte("[Interface]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.NewServerContext().WithHome(home)
+	if err := ctx.Reconcile(clitypes.OnStartIgnore); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := clitypes.LoadServiceStatuses(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("expected 1 status, got %d", len(statuses))
+	}
+
+	status := statuses[0]
+	if status.Name != clitypes.DefaultConnection || status.ID != 7 || status.IFace != "wg99" ||
+		status.Type != clitypes.ServiceTypeWireGuard || status.Backend != clitypes.ServiceBackendWgQuick || !status.KillSwitch {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, clitypes.LegacyStatusFile)); !os.IsNotExist(err) {
+		t.Fatalf("expected the legacy status file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(ctx.ConfigFilePath(clitypes.DefaultConnection)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconcileKeepsUnrecordedFiles(t *testing.T) {
+	home := t.TempDir()
+
+	path := filepath.Join(home, "wg0.conf")
+	if err := os.WriteFile(path, []byte("[Interface]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.NewServerContext().WithHome(home)
+	if err := ctx.Reconcile(clitypes.OnStartCleanup); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected the unrecorded config to be kept, got %v", err)
+	}
+}
+
+func TestReconcileRestoreWatches(t *testing.T) {
+	var (
+		home     = t.TempDir()
+		backend  = fake.NewBackend()
+		registry = services.NewRegistry()
+	)
+
+	if err := backend.Register(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.NewServerContext().
+		WithHome(home).
+		WithRegistry(registry)
+
+	supervisor := context.NewSupervisor(ctx)
+	limiter := context.NewLimiter(ctx.WithSupervisor(supervisor))
+
+	ctx = ctx.WithSupervisor(supervisor).
+		WithLimiter(limiter)
+
+	key, err := wireguardtypes.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &wireguardtypes.Config{
+		Interface: wireguardtypes.Interface{PrivateKey: *key},
+		Peers:     []wireguardtypes.Peer{{PublicKey: *key.Public()}},
+	}
+	if err := cfg.SaveToPath(ctx.ConfigFilePath("default")); err != nil {
+		t.Fatal(err)
+	}
+
+	status := clitypes.NewServiceStatus().
+		WithName("default").
+		WithType(clitypes.ServiceTypeWireGuard).
+		WithIFace("wgtest0").
+		WithLimits(&clitypes.LimitStatus{MaxBytes: 1 << 30})
+	if err := status.SaveToPath(ctx.StatusFilePath("default")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctx.Reconcile(clitypes.OnStartRestore); err != nil {
+		t.Fatal(err)
+	}
+
+	if !backend.IsUp("wgtest0") {
+		t.Fatal("expected the connection to be restored")
+	}
+	if supervisor.Status("default") == nil {
+		t.Fatal("expected the connection to be supervised")
+	}
+	if v := limiter.Status("default"); v == nil || v.MaxBytes != 1<<30 {
+		t.Fatalf("expected the limits to be watched, got %+v", v)
+	}
+}
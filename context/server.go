@@ -1,11 +1,58 @@
 package context
 
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/pkg/errors"
+
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	"github.com/sentinel-official/cli-client/services"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+type Signer struct {
+	Backend  string
+	Password string
+	From     string
+	Tx       *restrequests.Tx
+}
+
 type ServerContext struct {
-	home string
+	home       string
+	client     client.Context
+	supervisor *Supervisor
+	limiter    *Limiter
+	broker     *Broker
+	signer     *Signer
+	registry   *services.Registry
+	killSwitch KillSwitch
+	dialer     Dialer
+	query      *QueryContext
+	hooks      bool
+	end        bool
+	mutex      *sync.Mutex
+	locks      *sync.Map
 }
 
 func NewServerContext() ServerContext {
-	return ServerContext{}
+	return ServerContext{
+		registry:   services.NewDefaultRegistry(),
+		killSwitch: systemKillSwitch{},
+		dialer:     chainDialer{},
+		mutex:      &sync.Mutex{},
+		locks:      &sync.Map{},
+	}
 }
 
 func (c ServerContext) WithHome(v string) ServerContext {
@@ -13,6 +60,349 @@ func (c ServerContext) WithHome(v string) ServerContext {
 	return c
 }
 
+func (c ServerContext) WithClient(v client.Context) ServerContext {
+	c.client = v
+	return c
+}
+
+func (c ServerContext) WithSupervisor(v *Supervisor) ServerContext {
+	c.supervisor = v
+	return c
+}
+
+func (c ServerContext) WithLimiter(v *Limiter) ServerContext {
+	c.limiter = v
+	return c
+}
+
+func (c ServerContext) WithBroker(v *Broker) ServerContext {
+	c.broker = v
+	return c
+}
+
+func (c ServerContext) WithRegistry(v *services.Registry) ServerContext {
+	c.registry = v
+	return c
+}
+
+func (c ServerContext) WithKillSwitch(v KillSwitch) ServerContext {
+	c.killSwitch = v
+	return c
+}
+
+func (c ServerContext) WithDialer(v Dialer) ServerContext {
+	c.dialer = v
+	return c
+}
+
+func (c ServerContext) WithQuery(v *QueryContext) ServerContext {
+	c.query = v
+	return c
+}
+
+func (c ServerContext) WithSigner(v *Signer) ServerContext {
+	c.signer = v
+	return c
+}
+
+func (c ServerContext) WithConfigHooks(v bool) ServerContext {
+	c.hooks = v
+	return c
+}
+
+func (c ServerContext) WithEndSessions(v bool) ServerContext {
+	c.end = v
+	return c
+}
+
 func (c ServerContext) Home() string {
 	return c.home
 }
+
+func (c ServerContext) Client() client.Context {
+	return c.client
+}
+
+func (c ServerContext) Supervisor() *Supervisor {
+	return c.supervisor
+}
+
+func (c ServerContext) Limiter() *Limiter {
+	return c.limiter
+}
+
+func (c ServerContext) Broker() *Broker {
+	return c.broker
+}
+
+func (c ServerContext) Registry() *services.Registry {
+	return c.registry
+}
+
+func (c ServerContext) Dialer() Dialer {
+	return c.dialer
+}
+
+func (c ServerContext) Query() *QueryContext {
+	return c.query
+}
+
+func (c ServerContext) ConfigHooks() bool {
+	return c.hooks
+}
+
+func (c ServerContext) Signer() *Signer {
+	return c.signer
+}
+
+func (c ServerContext) Lock()   { c.mutex.Lock() }
+func (c ServerContext) Unlock() { c.mutex.Unlock() }
+
+func (c ServerContext) connectionMutex(name string) *sync.Mutex {
+	v, _ := c.locks.LoadOrStore(name, &sync.Mutex{})
+	return v.(*sync.Mutex)
+}
+
+func (c ServerContext) LockConnection(name string)   { c.connectionMutex(name).Lock() }
+func (c ServerContext) UnlockConnection(name string) { c.connectionMutex(name).Unlock() }
+
+func (c ServerContext) Keyring(backend, password string) (keyring.Keyring, error) {
+	return keyring.New(
+		sdk.KeyringServiceName(),
+		backend,
+		c.home,
+		strings.NewReader(strings.Repeat(password+"\n", 4)),
+	)
+}
+
+func (c ServerContext) NewTxContext(req *restrequests.Tx, backend, password, from string) (ctx TxContext, err error) {
+	accAddr, err := sdk.AccAddressFromBech32(from)
+	if err != nil {
+		return ctx, err
+	}
+
+	kr, err := c.Keyring(backend, password)
+	if err != nil {
+		return ctx, err
+	}
+
+	key, err := kr.KeyByAddress(accAddr)
+	if err != nil {
+		return ctx, err
+	}
+
+	ctx.QueryContext, err = NewQueryContext(c.client, req.RPCAddress)
+	if err != nil {
+		return ctx, err
+	}
+
+	ctx.GasPrices, err = sdk.ParseDecCoins(req.GasPrices)
+	if err != nil {
+		return ctx, err
+	}
+
+	ctx.KeyringContext = KeyringContext{
+		Client:  clitypes.NewHTTPClient(clitypes.Timeout),
+		Backend: backend,
+		Home:    c.home,
+	}.WithKeyring(kr)
+
+	ctx.BroadcastMode = req.BroadcastMode
+	ctx.ChainID = req.ChainID
+	ctx.From = key.GetName()
+	ctx.Gas = req.Gas
+	ctx.Memo = req.Memo
+
+	return ctx, nil
+}
+
+func (c ServerContext) StatusFilePath(name string) string {
+	return clitypes.ServiceStatusFilePath(c.home, name)
+}
+
+func (c ServerContext) ConfigFilePath(name string) string {
+	return filepath.Join(c.home, clitypes.ConfigDirname, fmt.Sprintf("%s.conf", name))
+}
+
+func (c ServerContext) RemoveConfigFile(name string) error {
+	if err := os.Remove(c.ConfigFilePath(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (c ServerContext) HistoryFilePath() string {
+	return filepath.Join(c.home, clitypes.HistoryFilename)
+}
+
+func (c ServerContext) NewHistoryRecord(name string, status *clitypes.ServiceStatus, reason string) clitypes.HistoryRecord {
+	record := clitypes.HistoryRecord{
+		Name:         name,
+		Type:         status.Type,
+		ID:           status.ID,
+		Node:         status.Node,
+		Subscription: status.Subscription,
+		From:         status.From,
+		StartAt:      status.StartAt,
+		EndAt:        time.Now(),
+		Upload:       status.Upload,
+		Download:     status.Download,
+		Reason:       reason,
+	}
+
+	service, err := c.Service(status)
+	if err != nil {
+		record.Errors = append(record.Errors, err.Error())
+	} else if service.IsUp() {
+		upload, download, err := service.Transfer()
+		if err != nil {
+			record.Errors = append(record.Errors, err.Error())
+		} else {
+			record.Upload += upload
+			record.Download += download
+		}
+	}
+
+	if c.supervisor != nil {
+		if reconnect := c.supervisor.Status(name); reconnect != nil {
+			for _, attempt := range reconnect.Attempts {
+				if attempt.Session == status.ID && attempt.Error != "" {
+					record.Errors = append(record.Errors, attempt.Error)
+				}
+			}
+		}
+	}
+
+	return record
+}
+
+func (c ServerContext) AppendHistoryRecord(record clitypes.HistoryRecord) error {
+	return clitypes.AppendHistoryRecord(c.HistoryFilePath(), record)
+}
+
+func (c ServerContext) CheckInterface(name, iFace string) error {
+	statuses, err := clitypes.LoadServiceStatuses(c.home)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.IFace == iFace && status.Name != name {
+			return fmt.Errorf("interface %s is used by connection %s", iFace, status.Name)
+		}
+	}
+
+	if _, err := net.InterfaceByName(iFace); err == nil {
+		return fmt.Errorf("interface %s already exists", iFace)
+	}
+
+	return nil
+}
+
+func (c ServerContext) NextInterface() (string, error) {
+	statuses, err := clitypes.LoadServiceStatuses(c.home)
+	if err != nil {
+		return "", err
+	}
+
+	used := make(map[string]bool)
+	for _, status := range statuses {
+		used[status.IFace] = true
+	}
+
+	for i := wireguardtypes.MaxInterfaceIndex; i >= 0; i-- {
+		name := fmt.Sprintf("%s%d", wireguardtypes.InterfacePrefix, i)
+		if used[name] {
+			continue
+		}
+		if _, err := net.InterfaceByName(name); err == nil {
+			continue
+		}
+
+		return name, nil
+	}
+
+	return "", errors.New("no free interface available")
+}
+
+func (c ServerContext) updateKillSwitch(down string, keep bool) error {
+	statuses, err := clitypes.LoadServiceStatuses(c.home)
+	if err != nil {
+		return err
+	}
+
+	var (
+		enabled   bool
+		iFaces    []string
+		endpoints []wireguardtypes.Endpoint
+		allowed   []wireguardtypes.IPNet
+	)
+
+	for _, status := range statuses {
+		if status.IsV2Ray() {
+			continue
+		}
+		if status.IFace == down {
+			if keep && status.KillSwitch {
+				enabled = true
+			}
+
+			continue
+		}
+
+		if !status.IsProxy() && !status.IsNamespace() {
+			iFaces = append(iFaces, status.IFace)
+		}
+		if status.Endpoint != "" {
+			endpoint, err := wireguardtypes.ParseEndpoint(status.Endpoint)
+			if err != nil {
+				return err
+			}
+
+			endpoints = append(endpoints, endpoint)
+		}
+
+		if !status.KillSwitch {
+			continue
+		}
+
+		enabled = true
+		for _, s := range status.Exclude {
+			item, err := wireguardtypes.ParseIPNet(s)
+			if err != nil {
+				return err
+			}
+
+			allowed = append(allowed, item)
+		}
+	}
+
+	if !enabled {
+		return c.killSwitch.Disable()
+	}
+
+	return c.killSwitch.Enable(iFaces, endpoints, allowed)
+}
+
+func (c ServerContext) Service(status *clitypes.ServiceStatus) (clitypes.Service, error) {
+	return c.NewService(status, nil)
+}
+
+func (c ServerContext) NewService(status *clitypes.ServiceStatus, cfg interface{}) (clitypes.Service, error) {
+	return c.newService(status, cfg, false)
+}
+
+func (c ServerContext) newService(status *clitypes.ServiceStatus, cfg interface{}, keep bool) (clitypes.Service, error) {
+	service, err := c.registry.New(c.home, status, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &killSwitchService{
+		Service: service,
+		ctx:     c,
+		iFace:   status.IFace,
+		keep:    keep,
+	}, nil
+}
@@ -1,21 +1,22 @@
 package context
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"net"
 	"net/http"
 	"net/url"
-	"path/filepath"
+	"strings"
 
 	"github.com/go-kit/kit/transport/http/jsonrpc"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	restresponses "github.com/sentinel-official/cli-client/rest/responses"
 	restroutes "github.com/sentinel-official/cli-client/rest/routes"
 	clitypes "github.com/sentinel-official/cli-client/types"
-	cliutils "github.com/sentinel-official/cli-client/utils"
 )
 
 type ServiceContext struct {
@@ -35,7 +36,7 @@ func NewServiceContextFromCmd(cmd *cobra.Command) (ctx ServiceContext, err error
 		return ctx, err
 	}
 
-	ctx.URL, err = cliutils.ReadLineFromFile(filepath.Join(ctx.Home, "url.txt"))
+	ctx.Client, ctx.URL, err = newClient(ctx.Client, ctx.Home)
 	if err != nil {
 		return ctx, err
 	}
@@ -53,20 +54,29 @@ func (c ServiceContext) WithURL(v string) ServiceContext {
 	return c
 }
 
-func (c *ServiceContext) GetStatus() (*clitypes.ServiceStatus, error) {
+func (c *ServiceContext) GetStatus(name string) (*restresponses.GetStatus, error) {
 	path, err := url.JoinPath(c.URL, restroutes.GetStatus)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.Post(path, jsonrpc.ContentType, nil)
+	buf, err := json.Marshal(
+		&restrequests.GetStatus{
+			Name: name,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Post(path, jsonrpc.ContentType, bytes.NewBuffer(buf))
 	if err != nil {
 		return nil, err
 	}
 
 	var (
 		body clitypes.RestResponseBody
-		res  clitypes.ServiceStatus
+		res  restresponses.GetStatus
 	)
 
 	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
@@ -76,7 +86,7 @@ func (c *ServiceContext) GetStatus() (*clitypes.ServiceStatus, error) {
 		return nil, fmt.Errorf(body.Error.Message)
 	}
 
-	buf, err := json.Marshal(body.Result)
+	buf, err = json.Marshal(body.Result)
 	if err != nil {
 		return nil, err
 	}
@@ -87,24 +97,49 @@ func (c *ServiceContext) GetStatus() (*clitypes.ServiceStatus, error) {
 	return &res, nil
 }
 
-func (c *ServiceContext) Connect(backend, password, from, to string, id uint64, info []byte, keys [][]byte, resolvers []net.IP) error {
-	path, err := url.JoinPath(c.URL, restroutes.Connect)
+func (c *ServiceContext) ExportConfig(name string) (string, error) {
+	path, err := url.JoinPath(c.URL, restroutes.ExportConfig)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	buf, err := json.Marshal(
-		&restrequests.Connect{
-			Backend:   backend,
-			Password:  password,
-			ID:        id,
-			From:      from,
-			To:        to,
-			Info:      info,
-			Keys:      keys,
-			Resolvers: resolvers,
+		&restrequests.ExportConfig{
+			Name: name,
 		},
 	)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.Post(path, jsonrpc.ContentType, bytes.NewBuffer(buf))
+	if err != nil {
+		return "", err
+	}
+
+	var body clitypes.RestResponseBody
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Error != nil {
+		return "", fmt.Errorf(body.Error.Message)
+	}
+
+	res, ok := body.Result.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid config")
+	}
+
+	return res, nil
+}
+
+func (c *ServiceContext) ImportConfig(req *restrequests.ImportConfig) error {
+	path, err := url.JoinPath(c.URL, restroutes.ImportConfig)
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(req)
 	if err != nil {
 		return err
 	}
@@ -125,13 +160,172 @@ func (c *ServiceContext) Connect(backend, password, from, to string, id uint64,
 	return nil
 }
 
-func (c *ServiceContext) Disconnect() error {
+func (c *ServiceContext) GetHistory(req *restrequests.GetHistory) (clitypes.HistoryRecords, error) {
+	path, err := url.JoinPath(c.URL, restroutes.GetHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Post(path, jsonrpc.ContentType, bytes.NewBuffer(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		body clitypes.RestResponseBody
+		res  clitypes.HistoryRecords
+	)
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Error != nil {
+		return nil, fmt.Errorf(body.Error.Message)
+	}
+
+	buf, err = json.Marshal(body.Result)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *ServiceContext) ListConnections() ([]restresponses.GetStatus, error) {
+	path, err := url.JoinPath(c.URL, restroutes.ListConnections)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Post(path, jsonrpc.ContentType, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		body clitypes.RestResponseBody
+		res  []restresponses.GetStatus
+	)
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Error != nil {
+		return nil, fmt.Errorf(body.Error.Message)
+	}
+
+	buf, err := json.Marshal(body.Result)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *ServiceContext) Connect(req *restrequests.Connect) error {
+	path, err := url.JoinPath(c.URL, restroutes.Connect)
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Post(path, jsonrpc.ContentType, bytes.NewBuffer(buf))
+	if err != nil {
+		return err
+	}
+
+	var body clitypes.RestResponseBody
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	if body.Error != nil {
+		return fmt.Errorf(body.Error.Message)
+	}
+
+	return nil
+}
+
+func (c *ServiceContext) ConnectToNode(req *restrequests.ConnectToNode, fn func(clitypes.Event) error) error {
+	path, err := url.JoinPath(c.URL, restroutes.ConnectToNode)
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	client := c.Client
+	client.Timeout = 0
+
+	resp, err := client.Post(path, jsonrpc.ContentType, bytes.NewBuffer(buf))
+	if err != nil {
+		return err
+	}
+
+	var (
+		connected bool
+		failed    error
+	)
+
+	if err := readEvents(resp, func(event clitypes.Event) error {
+		if event.Type != clitypes.EventTypeStep {
+			return nil
+		}
+		if event.State == clitypes.StepStateFailed && failed == nil {
+			failed = fmt.Errorf("%s: %s", event.Step, event.Error)
+		}
+		if event.Step == clitypes.StepConnect && event.State == clitypes.StepStateDone {
+			connected = true
+		}
+
+		return fn(event)
+	}); err != nil {
+		return err
+	}
+
+	if failed != nil {
+		return failed
+	}
+	if !connected {
+		return errors.New("connection closed before the connect step finished")
+	}
+
+	return nil
+}
+
+func (c *ServiceContext) Disconnect(name string) error {
 	path, err := url.JoinPath(c.URL, restroutes.Disconnect)
 	if err != nil {
 		return err
 	}
 
-	resp, err := c.Post(path, jsonrpc.ContentType, nil)
+	buf, err := json.Marshal(
+		&restrequests.Disconnect{
+			Name: name,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Post(path, jsonrpc.ContentType, bytes.NewBuffer(buf))
 	if err != nil {
 		return err
 	}
@@ -146,3 +340,56 @@ func (c *ServiceContext) Disconnect() error {
 
 	return nil
 }
+
+func (c *ServiceContext) StreamStatus(name string, fn func(clitypes.Event) error) error {
+	path, err := url.JoinPath(c.URL, restroutes.StreamStatus)
+	if err != nil {
+		return err
+	}
+
+	path = path + "?" + url.Values{"name": []string{name}}.Encode()
+
+	client := c.Client
+	client.Timeout = 0
+
+	resp, err := client.Get(path)
+	if err != nil {
+		return err
+	}
+
+	return readEvents(resp, fn)
+}
+
+func readEvents(resp *http.Response, fn func(clitypes.Event) error) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body clitypes.RestResponseBody
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return err
+		}
+		if body.Error != nil {
+			return fmt.Errorf(body.Error.Message)
+		}
+
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var event clitypes.Event
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
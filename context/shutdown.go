@@ -0,0 +1,107 @@
+package context
+
+import (
+	"log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	sessiontypes "github.com/sentinel-official/hub/x/session/types"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func (c ServerContext) Shutdown() error {
+	c.Lock()
+	defer c.Unlock()
+
+	statuses, err := clitypes.LoadServiceStatuses(c.home)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.ID == 0 {
+			continue
+		}
+
+		signer := c.sessionSigner(status)
+		if signer == nil {
+			continue
+		}
+
+		if c.supervisor != nil {
+			c.supervisor.Unwatch(status.Name)
+		}
+		if c.limiter != nil {
+			c.limiter.Unwatch(status.Name)
+		}
+
+		if err := c.teardown(status, clitypes.HistoryReasonShutdown); err != nil {
+			log.Printf("Failed to tear down connection %s, keeping session %d active: %s", status.Name, status.ID, err)
+			continue
+		}
+
+		log.Printf("Ending session %d of connection %s", status.ID, status.Name)
+		if err := c.endSession(signer, status.ID); err != nil {
+			log.Printf("Failed to end session %d: %s", status.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (c ServerContext) sessionSigner(status *clitypes.ServiceStatus) *Signer {
+	if c.end && c.supervisor != nil {
+		_, conn := c.supervisor.connection(status.Name)
+		if conn != nil && conn.Tx != nil && conn.From == status.From {
+			return &Signer{
+				Backend:  conn.Backend,
+				Password: conn.Password,
+				From:     conn.From,
+				Tx:       conn.Tx,
+			}
+		}
+	}
+	if c.signer != nil && c.signer.From == status.From {
+		return c.signer
+	}
+
+	return nil
+}
+
+func (c ServerContext) endSession(signer *Signer, id uint64) error {
+	accAddr, err := sdk.AccAddressFromBech32(signer.From)
+	if err != nil {
+		return err
+	}
+
+	tc, err := c.NewTxContext(signer.Tx, signer.Backend, signer.Password, signer.From)
+	if err != nil {
+		return err
+	}
+
+	session, err := tc.QueryActiveSession(accAddr)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.Id != id {
+		return nil
+	}
+
+	res, err := tc.SignMessagesAndBroadcastTx(
+		signer.Password,
+		sessiontypes.NewMsgEndRequest(
+			accAddr,
+			id,
+			0,
+		),
+	)
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return errors.New(res.RawLog)
+	}
+
+	return nil
+}
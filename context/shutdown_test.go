@@ -0,0 +1,88 @@
+package context_test
+
+import (
+	"testing"
+
+	"github.com/sentinel-official/cli-client/context"
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	"github.com/sentinel-official/cli-client/services"
+	"github.com/sentinel-official/cli-client/services/fake"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+const (
+	from = "sent1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu"
+)
+
+func TestShutdown(t *testing.T) {
+	var (
+		home     = t.TempDir()
+		backend  = fake.NewBackend()
+		registry = services.NewRegistry()
+	)
+
+	if err := backend.Register(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.NewServerContext().
+		WithHome(home).
+		WithRegistry(registry).
+		WithEndSessions(true)
+
+	supervisor := context.NewSupervisor(ctx)
+	ctx = ctx.WithSupervisor(supervisor)
+
+	for i, name := range []string{"supervised", "unsupervised"} {
+		status := clitypes.NewServiceStatus().
+			WithName(name).
+			WithType(clitypes.ServiceTypeWireGuard).
+			WithIFace("wgtest" + name[:1]).
+			WithID(uint64(i + 1)).
+			WithFrom(from)
+
+		if err := status.SaveToPath(ctx.StatusFilePath(name)); err != nil {
+			t.Fatal(err)
+		}
+
+		service, err := ctx.NewService(status, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := service.Up(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	supervisor.Watch(
+		context.Connection{
+			Name:    "supervised",
+			Backend: "test",
+			From:    from,
+			IFace:   "wgtests",
+			Tx:      &restrequests.Tx{RPCAddress: "http://127.0.0.1:1"},
+		},
+	)
+
+	if err := ctx.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	if backend.IsUp("wgtests") {
+		t.Fatal("expected the supervised connection to be torn down")
+	}
+	if !backend.IsUp("wgtestu") {
+		t.Fatal("expected the unsupervised connection to stay up")
+	}
+	if supervisor.Status("supervised") != nil {
+		t.Fatal("expected the supervised connection to be unwatched")
+	}
+
+	statuses, err := clitypes.LoadServiceStatuses(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 1 || statuses[0].Name != "unsupervised" {
+		t.Fatalf("expected only the unsupervised status, got %d", len(statuses))
+	}
+}
@@ -0,0 +1,451 @@
+package context
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	restrequests "github.com/sentinel-official/cli-client/rest/requests"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+	cliutils "github.com/sentinel-official/cli-client/utils"
+)
+
+const (
+	maxReconnectAttempts = 16
+)
+
+type Connection struct {
+	Name                string
+	Backend             string
+	Password            string
+	ID                  uint64
+	Subscription        uint64
+	From                string
+	To                  string
+	ServiceBackend      string
+	Mode                string
+	ProxyListen         string
+	Namespace           string
+	IFace               string
+	Resolvers           []net.IP
+	NoNodeResolver      bool
+	Include             []string
+	Exclude             []string
+	KillSwitch          bool
+	MTU                 uint16
+	PersistentKeepalive uint16
+	DNSSearch           []string
+	Tx                  *restrequests.Tx
+}
+
+type watch struct {
+	conn     Connection
+	state    string
+	failures int
+	attempts []clitypes.ReconnectAttempt
+	received int64
+	activeAt time.Time
+}
+
+type Supervisor struct {
+	ctx      ServerContext
+	interval time.Duration
+	timeout  time.Duration
+	retries  int
+	nodes    []string
+
+	mutex sync.Mutex
+
+	watches map[string]*watch
+}
+
+func NewSupervisor(ctx ServerContext) *Supervisor {
+	return &Supervisor{
+		ctx:     ctx,
+		watches: make(map[string]*watch),
+	}
+}
+
+func (s *Supervisor) WithInterval(v time.Duration) *Supervisor { s.interval = v; return s }
+func (s *Supervisor) WithTimeout(v time.Duration) *Supervisor  { s.timeout = v; return s }
+func (s *Supervisor) WithRetries(v int) *Supervisor            { s.retries = v; return s }
+func (s *Supervisor) WithNodes(v []string) *Supervisor         { s.nodes = v; return s }
+
+func (s *Supervisor) Watch(conn Connection) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.watches[conn.Name] = &watch{
+		conn:     conn,
+		state:    clitypes.ReconnectStateConnected,
+		activeAt: time.Now(),
+	}
+}
+
+func (s *Supervisor) Unwatch(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.watches, name)
+}
+
+func (s *Supervisor) Status(name string) *clitypes.ReconnectStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w, ok := s.watches[name]
+	if !ok {
+		return nil
+	}
+
+	return &clitypes.ReconnectStatus{
+		State:    w.state,
+		Failures: w.failures,
+		Attempts: append([]clitypes.ReconnectAttempt{}, w.attempts...),
+	}
+}
+
+func (s *Supervisor) Start() {
+	if s.interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, name := range s.names() {
+			s.check(name)
+		}
+	}
+}
+
+func (s *Supervisor) names() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	names := make([]string, 0, len(s.watches))
+	for name := range s.watches {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (s *Supervisor) connection(name string) (*watch, *Connection) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w, ok := s.watches[name]
+	if !ok {
+		return nil, nil
+	}
+
+	conn := w.conn
+	return w, &conn
+}
+
+func (s *Supervisor) isWatching(name string, w *watch) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.watches[name] == w
+}
+
+func (s *Supervisor) setState(name, state string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if w, ok := s.watches[name]; ok {
+		w.state = state
+	}
+}
+
+func (s *Supervisor) check(name string) {
+	current, conn := s.connection(name)
+	if conn == nil {
+		return
+	}
+
+	if !s.isStale(conn) {
+		s.setState(name, clitypes.ReconnectStateConnected)
+		return
+	}
+
+	s.setState(name, clitypes.ReconnectStateReconnecting)
+	s.ctx.Broker().Publish(clitypes.NewStateEvent(name, clitypes.StateConnecting, nil))
+
+	var (
+		node = conn.To
+		err  = s.reconnect(current, conn)
+	)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w, ok := s.watches[name]
+	if !ok || w != current {
+		return
+	}
+
+	attempt := clitypes.ReconnectAttempt{
+		Node:    conn.To,
+		Session: conn.ID,
+		Time:    time.Now(),
+	}
+
+	w.conn = *conn
+	if conn.To != node {
+		w.failures = 0
+	}
+
+	if err != nil {
+		s.ctx.Broker().Publish(clitypes.NewStateEvent(name, clitypes.StateError, err))
+
+		attempt.Error = err.Error()
+		w.failures++
+	} else {
+		s.ctx.Broker().Publish(clitypes.NewStateEvent(name, clitypes.StateUp, nil))
+
+		w.state = clitypes.ReconnectStateConnected
+		w.failures = 0
+		w.received = 0
+		w.activeAt = time.Now()
+	}
+
+	w.attempts = append(w.attempts, attempt)
+	if len(w.attempts) > maxReconnectAttempts {
+		w.attempts = w.attempts[len(w.attempts)-maxReconnectAttempts:]
+	}
+}
+
+func (c *Connection) status() *clitypes.ServiceStatus {
+	return clitypes.NewServiceStatus().
+		WithType(clitypes.ServiceTypeWireGuard).
+		WithBackend(c.ServiceBackend).
+		WithMode(c.Mode).
+		WithIFace(c.IFace).
+		WithProxy(c.ProxyListen).
+		WithNamespace(c.Namespace)
+}
+
+func (s *Supervisor) isStale(conn *Connection) bool {
+	service, err := s.ctx.Service(conn.status())
+	if err != nil {
+		return false
+	}
+
+	if !service.IsUp() {
+		return true
+	}
+
+	received, _, err := service.Transfer()
+	if err != nil {
+		return false
+	}
+
+	stats, err := service.Stats()
+	if err != nil {
+		return false
+	}
+
+	handshake := stats.LatestHandshake()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w, ok := s.watches[conn.Name]
+	if !ok {
+		return false
+	}
+
+	if received != w.received {
+		w.received = received
+		w.activeAt = time.Now()
+	}
+	if handshake.After(w.activeAt) {
+		w.activeAt = handshake
+	}
+
+	return time.Since(w.activeAt) > s.timeout
+}
+
+func (s *Supervisor) nextNode(conn *Connection) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w, ok := s.watches[conn.Name]
+	if !ok || w.failures < s.retries || len(s.nodes) == 0 {
+		return ""
+	}
+
+	for i, node := range s.nodes {
+		if node == conn.To {
+			return s.nodes[(i+1)%len(s.nodes)]
+		}
+	}
+
+	return s.nodes[0]
+}
+
+func (s *Supervisor) reconnect(w *watch, conn *Connection) error {
+	if conn.Tx == nil {
+		return errors.New("tx settings are not provided")
+	}
+
+	accAddr, err := sdk.AccAddressFromBech32(conn.From)
+	if err != nil {
+		return err
+	}
+
+	tc, err := s.ctx.NewTxContext(conn.Tx, conn.Backend, conn.Password, conn.From)
+	if err != nil {
+		return err
+	}
+
+	if node := s.nextNode(conn); node != "" && node != conn.To {
+		if conn.Subscription == 0 {
+			return errors.New("subscription is not provided")
+		}
+
+		id, err := s.ctx.dialer.StartSession(&tc, conn.Password, accAddr, conn.Subscription, node, 0)
+		if err != nil {
+			return err
+		}
+
+		conn.ID, conn.To = id, node
+	}
+
+	key, err := wireguardtypes.NewPrivateKey()
+	if err != nil {
+		return err
+	}
+
+	remoteURL, err := s.ctx.dialer.RemoteURL(&tc, conn.To)
+	if err != nil {
+		return err
+	}
+
+	info, err := s.ctx.dialer.Handshake(&tc, conn.Password, accAddr, remoteURL, conn.ID, key.Public().Bytes(), 58)
+	if err != nil {
+		return err
+	}
+
+	s.ctx.Lock()
+	defer s.ctx.Unlock()
+
+	if !s.isWatching(conn.Name, w) {
+		return nil
+	}
+
+	return s.up(conn, info, key)
+}
+
+func (s *Supervisor) up(conn *Connection, info []byte, key *wireguardtypes.Key) error {
+	previous := clitypes.NewServiceStatus()
+	if err := previous.LoadFromPath(s.ctx.StatusFilePath(conn.Name)); err != nil {
+		return err
+	}
+
+	record := s.ctx.NewHistoryRecord(conn.Name, previous, clitypes.HistoryReasonFailover)
+
+	current, err := s.ctx.newService(conn.status(), nil, true)
+	if err != nil {
+		return err
+	}
+
+	if current.IsUp() {
+		if err := current.PreDown(); err != nil {
+			return err
+		}
+		if err := current.Down(); err != nil {
+			return err
+		}
+		if err := current.PostDown(); err != nil {
+			return err
+		}
+	}
+
+	listenPort, err := cliutils.GetFreeUDPPort()
+	if err != nil {
+		return err
+	}
+
+	include, err := wireguardtypes.ParseIPNets(conn.Include)
+	if err != nil {
+		return err
+	}
+
+	exclude, err := wireguardtypes.ParseIPNets(conn.Exclude)
+	if err != nil {
+		return err
+	}
+
+	var (
+		cfg = wireguardtypes.NewConfigFromInfo(
+			conn.IFace,
+			info,
+			key,
+			listenPort,
+			!conn.NoNodeResolver,
+			conn.Resolvers,
+			include,
+			exclude,
+		)
+		status = conn.status().
+			WithID(conn.ID).
+			WithEndpoint(cfg.Peers[0].Endpoint.String()).
+			WithInclude(conn.Include).
+			WithExclude(conn.Exclude).
+			WithKillSwitch(conn.KillSwitch).
+			WithNode(conn.To).
+			WithSubscription(conn.Subscription).
+			WithFrom(conn.From).
+			WithLimits(previous.Limits)
+	)
+
+	cfg.Interface.MTU = conn.MTU
+	cfg.Interface.DNSSearch = conn.DNSSearch
+	cfg.Peers[0].PersistentKeepalive = conn.PersistentKeepalive
+
+	service, err := s.ctx.newService(status, cfg, true)
+	if err != nil {
+		return err
+	}
+
+	if previous.ID == conn.ID {
+		status = status.
+			WithStartAt(previous.StartAt).
+			WithUpload(record.Upload).
+			WithDownload(record.Download)
+	} else {
+		if previous.ID != 0 {
+			if err := s.ctx.AppendHistoryRecord(record); err != nil {
+				return err
+			}
+		}
+
+		status = status.WithStartAt(time.Now())
+	}
+
+	if err := status.SaveToPath(s.ctx.StatusFilePath(conn.Name)); err != nil {
+		return err
+	}
+
+	if err := service.PreUp(); err != nil {
+		return err
+	}
+	if err := service.Up(); err != nil {
+		return err
+	}
+	if err := service.PostUp(); err != nil {
+		return err
+	}
+	return cfg.SaveToPath(s.ctx.ConfigFilePath(conn.Name))
+}
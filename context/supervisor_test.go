@@ -0,0 +1,114 @@
+package context_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/services"
+	"github.com/sentinel-official/cli-client/services/fake"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+type killSwitch struct {
+	enabled []bool
+	iFaces  []string
+}
+
+func (k *killSwitch) Enable(iFaces []string, _ []wireguardtypes.Endpoint, _ []wireguardtypes.IPNet) error {
+	k.enabled = append(k.enabled, true)
+	k.iFaces = iFaces
+	return nil
+}
+
+func (k *killSwitch) Disable() error {
+	k.enabled = append(k.enabled, false)
+	k.iFaces = nil
+	return nil
+}
+
+func info() []byte {
+	v := make([]byte, 58)
+	copy(v[0:4], net.IPv4(10, 8, 0, 2).To4())
+	copy(v[4:20], net.ParseIP("fd00::2"))
+	copy(v[20:24], net.IPv4(192, 0, 2, 1).To4())
+	v[24], v[25] = 0xca, 0x6c
+
+	return v
+}
+
+func TestSupervisorUpKeepsKillSwitch(t *testing.T) {
+	var (
+		home     = t.TempDir()
+		backend  = fake.NewBackend()
+		registry = services.NewRegistry()
+		ks       = &killSwitch{}
+	)
+
+	if err := backend.Register(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.NewServerContext().
+		WithHome(home).
+		WithRegistry(registry).
+		WithKillSwitch(ks)
+
+	status := clitypes.NewServiceStatus().
+		WithName("default").
+		WithType(clitypes.ServiceTypeWireGuard).
+		WithIFace("wgtest0").
+		WithID(7).
+		WithKillSwitch(true).
+		WithFrom(from)
+
+	if err := status.SaveToPath(ctx.StatusFilePath("default")); err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := ctx.NewService(status, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Up(); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.PostUp(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := context.Connection{
+		Name:       "default",
+		ID:         7,
+		From:       from,
+		IFace:      "wgtest0",
+		KillSwitch: true,
+	}
+
+	supervisor := context.NewSupervisor(ctx)
+	supervisor.Watch(conn)
+
+	key, err := wireguardtypes.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := supervisor.Up(conn, info(), key); err != nil {
+		t.Fatal(err)
+	}
+
+	if !backend.IsUp("wgtest0") {
+		t.Fatal("expected the connection to be up")
+	}
+	if len(ks.enabled) < 3 {
+		t.Fatalf("expected the kill switch to be updated on teardown and bring-up, got %v", ks.enabled)
+	}
+	for i, enabled := range ks.enabled {
+		if !enabled {
+			t.Fatalf("expected the kill switch to stay enabled, got disabled on update %d", i)
+		}
+	}
+	if len(ks.iFaces) != 1 || ks.iFaces[0] != "wgtest0" {
+		t.Fatalf("expected the kill switch to allow wgtest0, got %v", ks.iFaces)
+	}
+}
@@ -0,0 +1,127 @@
+package context
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+	cliutils "github.com/sentinel-official/cli-client/utils"
+)
+
+type tokenTransport struct {
+	base  http.RoundTripper
+	host  string
+	token string
+}
+
+func NewTokenTransport(base http.RoundTripper, host, token string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &tokenTransport{
+		base:  base,
+		host:  host,
+		token: token,
+	}
+}
+
+func (t *tokenTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !strings.EqualFold(r.URL.Host, t.host) {
+		return t.base.RoundTrip(r)
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+t.token)
+
+	return t.base.RoundTrip(r)
+}
+
+func withSocket(c http.Client, path string) http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if v, ok := c.Transport.(*http.Transport); ok {
+		transport = v.Clone()
+	}
+
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	}
+
+	c.Transport = transport
+	return c
+}
+
+func withFingerprint(c http.Client, fingerprint string) http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if v, ok := c.Transport.(*http.Transport); ok {
+		transport = v.Clone()
+	}
+
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("server did not present a certificate")
+			}
+			if !strings.EqualFold(cliutils.Fingerprint(state.PeerCertificates[0].Raw), fingerprint) {
+				return fmt.Errorf("certificate of the server does not match the fingerprint %s", fingerprint)
+			}
+
+			return nil
+		},
+	}
+
+	c.Transport = transport
+	return c
+}
+
+func newClient(c http.Client, home string) (http.Client, string, error) {
+	lines, err := cliutils.ReadLinesFromFile(filepath.Join(home, clitypes.URLFilename))
+	if err != nil {
+		return c, "", err
+	}
+	if len(lines) == 0 {
+		return c, "", fmt.Errorf("file %s is empty", clitypes.URLFilename)
+	}
+
+	s := lines[0]
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return c, "", err
+	}
+
+	if u.Scheme == "https" && len(lines) > 1 {
+		c = withFingerprint(c, lines[1])
+	}
+
+	host := u.Host
+
+	unix := u.Scheme == clitypes.UnixScheme
+	if unix {
+		c = withSocket(c, u.Path)
+		host, s = "localhost", "http://localhost"+clitypes.APIPathPrefix
+	}
+
+	token, err := cliutils.ReadLineFromFile(filepath.Join(home, clitypes.TokenFilename))
+	if err != nil {
+		if unix && (os.IsNotExist(err) || os.IsPermission(err)) {
+			return c, s, nil
+		}
+
+		return c, "", err
+	}
+
+	c.Transport = NewTokenTransport(c.Transport, host, token)
+	return c, s, nil
+}
@@ -0,0 +1,88 @@
+package context_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/x/node/handshake"
+)
+
+type recorder struct {
+	*httptest.Server
+	header chan string
+}
+
+func newRecorder(t *testing.T) *recorder {
+	r := &recorder{header: make(chan string, 8)}
+	r.Server = httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r.header <- req.Header.Get("Authorization")
+			w.WriteHeader(http.StatusNotFound)
+		}),
+	)
+
+	t.Cleanup(r.Close)
+	return r
+}
+
+func newTokenClient(t *testing.T, server *recorder) http.Client {
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return http.Client{
+		Transport: context.NewTokenTransport(nil, u.Host, "secret"),
+	}
+}
+
+func TestTokenTransport(t *testing.T) {
+	var (
+		server = newRecorder(t)
+		node   = newRecorder(t)
+		client = newTokenClient(t, server)
+	)
+
+	tests := []struct {
+		url  string
+		in   chan string
+		want string
+	}{
+		{server.URL + "/api/v1/Service.GetStatus", server.header, "Bearer secret"},
+		{node.URL + "/status", node.header, ""},
+	}
+
+	for _, tt := range tests {
+		resp, err := client.Get(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		if got := <-tt.in; got != tt.want {
+			t.Fatalf("%s: expected authorization %q, got %q", tt.url, tt.want, got)
+		}
+	}
+}
+
+func TestTokenTransportHandshake(t *testing.T) {
+	var (
+		server = newRecorder(t)
+		node   = newRecorder(t)
+	)
+
+	_, err := handshake.NewClient().
+		WithHTTPClient(newTokenClient(t, server)).
+		WithRetries(0).
+		Handshake(node.URL, "sent1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu", 1, []byte{1}, []byte{2})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if got := <-node.header; got != "" {
+		t.Fatalf("expected no authorization on the node, got %q", got)
+	}
+}
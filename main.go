@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"os"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/version"
@@ -38,20 +40,30 @@ func main() {
 	}
 
 	root.AddCommand(
+		cmd.ConfigCmd(),
 		cmd.ConnectCmd(),
+		cmd.ConnectionsCmd(),
 		cmd.DisconnectCmd(),
+		cmd.ExecCmd(),
+		cmd.HistoryCmd(),
 		cmd.KeysCmd(),
 		cmd.QueryCommand(),
 		cmd.StartCmd(),
+		cmd.StatusCmd(),
 		cmd.TxCommand(),
 		version.NewVersionCommand(),
 	)
 
-	_ = root.ExecuteContext(
+	err := root.ExecuteContext(
 		context.WithValue(
 			context.Background(),
 			client.ClientContextKey,
 			&client.Context{},
 		),
 	)
+
+	var e *cmd.ExitError
+	if errors.As(err, &e) {
+		os.Exit(e.Code)
+	}
 }
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/rest/requests"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+	cliutils "github.com/sentinel-official/cli-client/utils"
+)
+
+func importedInclude(cfg *wireguardtypes.Config) []wireguardtypes.IPNet {
+	var items []wireguardtypes.IPNet
+	for _, peer := range cfg.Peers {
+		for _, ip := range peer.AllowedIPs {
+			if ip.Net == 0 {
+				return nil
+			}
+
+			items = append(items, ip)
+		}
+	}
+
+	return items
+}
+
+func ExportConfig(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewExportConfig(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		status := clitypes.NewServiceStatus()
+		if err := status.LoadFromPath(ctx.StatusFilePath(req.Name)); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		if status.IFace == "" {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusNotFound,
+				clitypes.NewRestError(1004, fmt.Sprintf("connection %s does not exist", req.Name)),
+			)
+			return
+		}
+		if status.IsV2Ray() {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1005, fmt.Sprintf("connection %s is not a WireGuard connection", req.Name)),
+			)
+			return
+		}
+
+		data, err := os.ReadFile(ctx.ConfigFilePath(req.Name))
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1006, err.Error()),
+			)
+			return
+		}
+
+		cfg, err := wireguardtypes.ParseWgQuick(data)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1007, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, cfg.ToWgQuick())
+	}
+}
+
+func ImportConfig(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewImportConfig(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		ctx.LockConnection(req.Name)
+		defer ctx.UnlockConnection(req.Name)
+
+		ctx.Lock()
+		defer ctx.Unlock()
+
+		cfg, warnings, err := wireguardtypes.ParseWgQuickWithWarnings([]byte(req.Config))
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+		if cfg.HasScripts() && !ctx.ConfigHooks() {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1015, "config contains PreUp, PostUp, PreDown or PostDown commands, which are not allowed by the server"),
+			)
+			return
+		}
+
+		for _, warning := range warnings {
+			log.Printf("Importing the config of connection %s: %s", req.Name, warning)
+		}
+
+		var (
+			status         = clitypes.NewServiceStatus()
+			statusFilePath = ctx.StatusFilePath(req.Name)
+		)
+
+		if err := status.LoadFromPath(statusFilePath); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		if status.IFace != "" {
+			if isServiceUp(ctx, status) {
+				cliutils.WriteErrorToResponseBody(
+					w, http.StatusBadRequest,
+					clitypes.NewRestError(1004, fmt.Sprintf("connection %s is already running on interface %s", req.Name, status.IFace)),
+				)
+				return
+			}
+		}
+
+		ctx.Supervisor().Unwatch(req.Name)
+		ctx.Limiter().Unwatch(req.Name)
+
+		ctx.Broker().Publish(clitypes.NewStateEvent(req.Name, clitypes.StateConnecting, nil))
+
+		rec := newStateRecorder(w)
+		defer rec.Publish(ctx, req.Name, clitypes.StateUp)
+
+		w = rec
+
+		var (
+			include  = importedInclude(cfg)
+			includes = make([]string, 0, len(include))
+		)
+
+		for _, item := range include {
+			includes = append(includes, item.String())
+		}
+
+		if err := ctx.CheckRoutingScope(req.Name, include); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1005, err.Error()),
+			)
+			return
+		}
+
+		endpoint, err := net.ResolveUDPAddr("udp", cfg.Peers[0].Endpoint.String())
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1006, err.Error()),
+			)
+			return
+		}
+
+		iFace, err := ctx.NextInterface()
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1007, err.Error()),
+			)
+			return
+		}
+
+		cfg.Name = iFace
+
+		status = clitypes.NewServiceStatus().
+			WithType(clitypes.ServiceTypeWireGuard).
+			WithBackend(ctx.Registry().Default(clitypes.ServiceTypeWireGuard)).
+			WithIFace(iFace).
+			WithEndpoint(endpoint.String()).
+			WithInclude(includes).
+			WithKillSwitch(req.KillSwitch).
+			WithStartAt(time.Now())
+
+		service, err := ctx.NewService(status, cfg)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1014, err.Error()),
+			)
+			return
+		}
+
+		if err := status.SaveToPath(statusFilePath); err != nil {
+			ctx.RollbackService(req.Name, service)
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1008, err.Error()),
+			)
+			return
+		}
+
+		if err := service.PreUp(); err != nil {
+			ctx.RollbackService(req.Name, service)
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1010, err.Error()),
+			)
+			return
+		}
+		if err := service.Up(); err != nil {
+			ctx.RollbackService(req.Name, service)
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1011, err.Error()),
+			)
+			return
+		}
+		if err := service.PostUp(); err != nil {
+			ctx.RollbackService(req.Name, service)
+
+			code := 1012
+			if context.IsKillSwitchError(err) {
+				code = 1009
+			}
+
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(code, err.Error()),
+			)
+			return
+		}
+
+		if err := cfg.SaveToPath(ctx.ConfigFilePath(req.Name)); err != nil {
+			ctx.RollbackService(req.Name, service)
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1013, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/rest/routes"
+	"github.com/sentinel-official/cli-client/services/fake"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func wgQuickConfig(t *testing.T, lines ...string) string {
+	privateKey, err := wireguardtypes.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := []string{
+		"[Interface]",
+		"PrivateKey = " + privateKey.String(),
+		"Address = 10.8.0.2/32",
+	}
+	items = append(items, lines...)
+	items = append(items,
+		"[Peer]",
+		"PublicKey = "+privateKey.Public().String(),
+		"AllowedIPs = 10.0.0.0/8",
+		"Endpoint = 192.0.2.1:51820",
+	)
+
+	return strings.Join(items, "\n")
+}
+
+func importRequest(config string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":   "default",
+		"config": config,
+	}
+}
+
+func TestImportConfig(t *testing.T) {
+	s := newServer(t)
+
+	code, res, keys := s.post(t, routes.ImportConfig, importRequest(wgQuickConfig(t, "Table = off", "SaveConfig = true")))
+	expectSuccess(t, code, res, keys)
+
+	if names := s.backend.Names(); len(names) != 1 {
+		t.Fatalf("expected one device, got %v", names)
+	}
+}
+
+func TestImportConfigHooks(t *testing.T) {
+	config := wgQuickConfig(t, "PostUp = touch /tmp/owned")
+
+	s := newServer(t)
+
+	code, res, _ := s.post(t, routes.ImportConfig, importRequest(config))
+	expectError(t, code, res, http.StatusBadRequest, 1015)
+
+	if names := s.backend.Names(); len(names) != 0 {
+		t.Fatalf("expected no devices, got %v", names)
+	}
+	if _, err := os.Stat(clitypes.ServiceStatusFilePath(s.home, "default")); !os.IsNotExist(err) {
+		t.Fatalf("expected no status file, got %v", err)
+	}
+
+	s = newServer(t, func(ctx context.ServerContext) context.ServerContext {
+		return ctx.WithConfigHooks(true)
+	})
+
+	code, res, keys := s.post(t, routes.ImportConfig, importRequest(config))
+	expectSuccess(t, code, res, keys)
+}
+
+func TestImportConfigFailure(t *testing.T) {
+	tests := []struct {
+		op   string
+		code int
+	}{
+		{fake.OpPreUp, 1010},
+		{fake.OpUp, 1011},
+		{fake.OpPostUp, 1012},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			s := newServer(t)
+			s.backend.Fail(tt.op, errors.New("injected"))
+
+			code, res, _ := s.post(t, routes.ImportConfig, importRequest(wgQuickConfig(t)))
+			expectError(t, code, res, http.StatusInternalServerError, tt.code)
+
+			if names := s.backend.Names(); len(names) != 0 {
+				t.Fatalf("expected no devices, got %v", names)
+			}
+			if _, err := os.Stat(clitypes.ServiceStatusFilePath(s.home, "default")); !os.IsNotExist(err) {
+				t.Fatalf("expected no status file, got %v", err)
+			}
+		})
+	}
+}
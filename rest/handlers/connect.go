@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/rest/requests"
+	v2raytypes "github.com/sentinel-official/cli-client/services/v2ray/types"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+	cliutils "github.com/sentinel-official/cli-client/utils"
+)
+
+type progress struct {
+	ctx     *context.ServerContext
+	w       http.ResponseWriter
+	flusher http.Flusher
+	name    string
+	session uint64
+}
+
+func (p *progress) publish(step, state string, err error) {
+	event := clitypes.NewStepEvent(p.name, step, state, err)
+	event.Session = p.session
+
+	p.ctx.Broker().Publish(event)
+	if err := cliutils.WriteEventToResponseBody(p.w, event); err == nil {
+		p.flusher.Flush()
+	}
+}
+
+func (p *progress) run(step string, fn func() error) error {
+	p.publish(step, clitypes.StepStateStarted, nil)
+	if err := fn(); err != nil {
+		p.publish(step, clitypes.StepStateFailed, err)
+		return err
+	}
+
+	p.publish(step, clitypes.StepStateDone, nil)
+	return nil
+}
+
+type dialTarget struct {
+	remoteURL string
+	host      string
+	nodeType  uint64
+	key       []byte
+	peerKey   []byte
+	infoSize  int
+}
+
+func queryTarget(ctx *context.ServerContext, tc *context.TxContext, req *requests.ConnectToNode) (*dialTarget, error) {
+	rawURL, err := ctx.Dialer().RemoteURL(tc, req.Node)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := ctx.Dialer().NodeInfo(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Mode != clitypes.ServiceModeTunnel && info.Type != clitypes.ServiceTypeWireGuard {
+		return nil, fmt.Errorf("mode %s is not supported by node type %d", req.Mode, info.Type)
+	}
+
+	target := &dialTarget{
+		remoteURL: rawURL,
+		host:      remoteURL.Hostname(),
+		nodeType:  info.Type,
+	}
+
+	switch info.Type {
+	case clitypes.ServiceTypeWireGuard:
+		key, err := wireguardtypes.NewPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+
+		target.key, target.peerKey, target.infoSize = key.Bytes(), key.Public().Bytes(), 58
+	case clitypes.ServiceTypeV2Ray:
+		uid, err := v2raytypes.NewUUID()
+		if err != nil {
+			return nil, err
+		}
+
+		target.key, target.peerKey, target.infoSize = uid.Bytes(), append([]byte{v2raytypes.ProxyVMess}, uid.Bytes()...), 3
+	default:
+		return nil, fmt.Errorf("unsupported node type %d", info.Type)
+	}
+
+	return target, nil
+}
+
+func disconnectIfExists(ctx *context.ServerContext, name string) error {
+	status := clitypes.NewServiceStatus()
+	if err := status.LoadFromPath(ctx.StatusFilePath(name)); err != nil {
+		return err
+	}
+	if status.IFace == "" {
+		return nil
+	}
+
+	return ctx.Disconnect(name)
+}
+
+func ConnectToNode(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewConnectToNode(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, "streaming is not supported"),
+			)
+			return
+		}
+
+		kr, err := ctx.Keyring(req.Backend, req.Password)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1004, err.Error()),
+			)
+			return
+		}
+
+		key, err := kr.Key(req.From)
+		if err != nil {
+			accAddr, bech32Err := sdk.AccAddressFromBech32(req.From)
+			if bech32Err == nil {
+				key, err = kr.KeyByAddress(accAddr)
+			}
+		}
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1005, err.Error()),
+			)
+			return
+		}
+
+		from := key.GetAddress()
+
+		tc, err := ctx.NewTxContext(req.Tx, req.Backend, req.Password, from.String())
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1006, err.Error()),
+			)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		ctx.LockConnection(req.Name)
+		defer ctx.UnlockConnection(req.Name)
+
+		p := &progress{
+			ctx:     ctx,
+			w:       w,
+			flusher: flusher,
+			name:    req.Name,
+		}
+
+		if err := p.run(clitypes.StepDisconnect, func() error {
+			return disconnectIfExists(ctx, req.Name)
+		}); err != nil {
+			return
+		}
+
+		var target *dialTarget
+		if err := p.run(clitypes.StepNode, func() (err error) {
+			target, err = queryTarget(ctx, &tc, req)
+			return err
+		}); err != nil {
+			return
+		}
+
+		var id uint64
+		if err := p.run(clitypes.StepSession, func() (err error) {
+			id, err = ctx.Dialer().StartSession(&tc, req.Password, from, req.Subscription, req.Node, req.Rating)
+			p.session = id
+			return err
+		}); err != nil {
+			return
+		}
+
+		rollback := func() {
+			_ = p.run(clitypes.StepRollback, func() error {
+				var (
+					disconnectErr = disconnectIfExists(ctx, req.Name)
+					endErr        = ctx.Dialer().EndSession(&tc, req.Password, from, id)
+				)
+
+				if disconnectErr != nil {
+					return disconnectErr
+				}
+
+				return endErr
+			})
+		}
+
+		var info []byte
+		if err := p.run(clitypes.StepHandshake, func() (err error) {
+			info, err = ctx.Dialer().Handshake(&tc, req.Password, from, target.remoteURL, id, target.peerKey, target.infoSize)
+			return err
+		}); err != nil {
+			rollback()
+			return
+		}
+
+		if err := p.run(clitypes.StepConnect, func() error {
+			connect := req.Connect(target.nodeType, target.host, id, from.String(), info, target.key)
+			if err := connect.Validate(); err != nil {
+				return err
+			}
+
+			return ctx.Connect(connect)
+		}); err != nil {
+			rollback()
+			return
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package handlers_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/go-bip39"
+	"github.com/pkg/errors"
+	hubtypes "github.com/sentinel-official/hub/types"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/rest/routes"
+	"github.com/sentinel-official/cli-client/services/fake"
+	clitypes "github.com/sentinel-official/cli-client/types"
+	clinodetypes "github.com/sentinel-official/cli-client/x/node/types"
+)
+
+func (s *server) stream(t *testing.T, route string, body interface{}) []clitypes.Event {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, clitypes.APIPathPrefix+route, &buf)
+	)
+
+	s.router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var (
+		events  []clitypes.Event
+		scanner = bufio.NewScanner(w.Body)
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var event clitypes.Event
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Type == clitypes.EventTypeStep {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+func stepsOf(events []clitypes.Event) []string {
+	v := make([]string, 0, len(events))
+	for _, event := range events {
+		v = append(v, event.Step+":"+event.State)
+	}
+
+	return v
+}
+
+func (s *server) addKey(t *testing.T) sdk.AccAddress {
+	kr, err := keyring.New(sdk.KeyringServiceName(), keyring.BackendTest, s.home, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := kr.NewAccount("test", mnemonic, "", sdk.FullFundraiserPath, hd.Secp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key.GetAddress()
+}
+
+func connectToNodeRequest(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"backend":      "test",
+		"name":         name,
+		"subscription": 1,
+		"from":         "test",
+		"node":         hubtypes.NodeAddress(bytes.Repeat([]byte{1}, 20)).String(),
+		"iface":        iFace,
+		"tx": map[string]interface{}{
+			"broadcast_mode": "block",
+			"chain_id":       "sentinelhub-2",
+			"gas":            200000,
+			"gas_prices":     "0.1udvpn",
+			"rpc_address":    "http://127.0.0.1:26657",
+		},
+	}
+}
+
+func steps(pairs ...string) []string {
+	var v []string
+	for i := 0; i < len(pairs); i += 2 {
+		v = append(v, pairs[i]+":"+pairs[i+1])
+	}
+
+	return v
+}
+
+type dialer struct {
+	id           uint64
+	info         []byte
+	handshakeErr error
+	ended        []uint64
+}
+
+func (d *dialer) RemoteURL(*context.TxContext, string) (string, error) {
+	return "https://192.0.2.1:8585", nil
+}
+
+func (d *dialer) NodeInfo(string) (clinodetypes.NodeInfo, error) {
+	return clinodetypes.NodeInfo{Type: clitypes.ServiceTypeWireGuard}, nil
+}
+
+func (d *dialer) StartSession(*context.TxContext, string, sdk.AccAddress, uint64, string, uint64) (uint64, error) {
+	return d.id, nil
+}
+
+func (d *dialer) EndSession(_ *context.TxContext, _ string, _ sdk.AccAddress, id uint64) error {
+	d.ended = append(d.ended, id)
+	return nil
+}
+
+func (d *dialer) Handshake(*context.TxContext, string, sdk.AccAddress, string, uint64, []byte, int) ([]byte, error) {
+	return d.info, d.handshakeErr
+}
+
+func withDialer(d context.Dialer) func(context.ServerContext) context.ServerContext {
+	return func(c context.ServerContext) context.ServerContext {
+		return c.WithDialer(d)
+	}
+}
+
+func TestConnectToNode(t *testing.T) {
+	d := &dialer{id: 7, info: info()}
+	s := newServer(t, withDialer(d))
+	accAddr := s.addKey(t)
+
+	events := s.stream(t, routes.ConnectToNode, connectToNodeRequest("default"))
+	got := stepsOf(events)
+	expected := steps(
+		clitypes.StepDisconnect, clitypes.StepStateStarted,
+		clitypes.StepDisconnect, clitypes.StepStateDone,
+		clitypes.StepNode, clitypes.StepStateStarted,
+		clitypes.StepNode, clitypes.StepStateDone,
+		clitypes.StepSession, clitypes.StepStateStarted,
+		clitypes.StepSession, clitypes.StepStateDone,
+		clitypes.StepHandshake, clitypes.StepStateStarted,
+		clitypes.StepHandshake, clitypes.StepStateDone,
+		clitypes.StepConnect, clitypes.StepStateStarted,
+		clitypes.StepConnect, clitypes.StepStateDone,
+	)
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected steps %v, got %v", expected, got)
+	}
+
+	for _, event := range events[5:] {
+		if event.Session != 7 {
+			t.Fatalf("expected session 7 on event %s:%s, got %d", event.Step, event.State, event.Session)
+		}
+	}
+
+	if !s.backend.IsUp(iFace) {
+		t.Fatalf("expected device %s to be up", iFace)
+	}
+	if len(d.ended) != 0 {
+		t.Fatalf("expected no ended sessions, got %v", d.ended)
+	}
+
+	status := clitypes.NewServiceStatus()
+	if err := status.LoadFromPath(clitypes.ServiceStatusFilePath(s.home, "default")); err != nil {
+		t.Fatal(err)
+	}
+	if status.IFace != iFace || status.ID != 7 || status.From != accAddr.String() {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestConnectToNodeRollback(t *testing.T) {
+	tests := []struct {
+		name   string
+		step   string
+		op     string
+		failed error
+	}{
+		{"handshake", clitypes.StepHandshake, "", errors.New("injected")},
+		{"up", clitypes.StepConnect, fake.OpUp, nil},
+		{"post_up", clitypes.StepConnect, fake.OpPostUp, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &dialer{id: 7, info: info(), handshakeErr: tt.failed}
+			s := newServer(t, withDialer(d))
+			s.addKey(t)
+
+			if tt.op != "" {
+				s.backend.Fail(tt.op, errors.New("injected"))
+			}
+
+			got := stepsOf(s.stream(t, routes.ConnectToNode, connectToNodeRequest("default")))
+			if len(got) < 3 {
+				t.Fatalf("expected at least 3 steps, got %v", got)
+			}
+
+			expected := steps(
+				tt.step, clitypes.StepStateFailed,
+				clitypes.StepRollback, clitypes.StepStateStarted,
+				clitypes.StepRollback, clitypes.StepStateDone,
+			)
+			if tail := got[len(got)-3:]; !reflect.DeepEqual(tail, expected) {
+				t.Fatalf("expected steps to end with %v, got %v", expected, got)
+			}
+
+			if !reflect.DeepEqual(d.ended, []uint64{7}) {
+				t.Fatalf("expected session 7 to be ended, got %v", d.ended)
+			}
+			if names := s.backend.Names(); len(names) != 0 {
+				t.Fatalf("expected no devices, got %v", names)
+			}
+
+			status := clitypes.NewServiceStatus()
+			if err := status.LoadFromPath(clitypes.ServiceStatusFilePath(s.home, "default")); err != nil {
+				t.Fatal(err)
+			}
+			if status.IFace != "" {
+				t.Fatalf("expected no status, got %+v", status)
+			}
+		})
+	}
+}
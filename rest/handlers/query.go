@@ -0,0 +1,610 @@
+package handlers
+
+import (
+	"net/http"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	hubtypes "github.com/sentinel-official/hub/types"
+	nodetypes "github.com/sentinel-official/hub/x/node/types"
+	hubplantypes "github.com/sentinel-official/hub/x/plan/types"
+	hubsessiontypes "github.com/sentinel-official/hub/x/session/types"
+	hubsubscriptiontypes "github.com/sentinel-official/hub/x/subscription/types"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/rest/requests"
+	clitypes "github.com/sentinel-official/cli-client/types"
+	cliutils "github.com/sentinel-official/cli-client/utils"
+	deposittypes "github.com/sentinel-official/cli-client/x/deposit/types"
+	clinodetypes "github.com/sentinel-official/cli-client/x/node/types"
+	plantypes "github.com/sentinel-official/cli-client/x/plan/types"
+	providertypes "github.com/sentinel-official/cli-client/x/provider/types"
+	sessiontypes "github.com/sentinel-official/cli-client/x/session/types"
+	subscriptiontypes "github.com/sentinel-official/cli-client/x/subscription/types"
+)
+
+func newNodes(v nodetypes.Nodes, withInfo bool) []clinodetypes.Node {
+	if !withInfo {
+		items := make([]clinodetypes.Node, len(v))
+		for i := 0; i < len(v); i++ {
+			items[i] = clinodetypes.NewNodeFromRaw(&v[i])
+		}
+
+		return items
+	}
+
+	items, _ := clinodetypes.FetchNodesWithInfo(v, clitypes.Timeout)
+	return items
+}
+
+func GetActiveSession(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetActiveSession(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		accAddr, err := sdk.AccAddressFromBech32(req.Address)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryActiveSession(accAddr)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1004, err.Error()),
+			)
+			return
+		}
+		if result == nil {
+			cliutils.WriteResultToResponseBody(w, http.StatusOK, nil)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, sessiontypes.NewSessionFromRaw(result))
+	}
+}
+
+func GetDeposit(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetDeposit(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		accAddr, err := sdk.AccAddressFromBech32(req.Address)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryDeposit(accAddr)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1004, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, deposittypes.NewDepositFromRaw(result))
+	}
+}
+
+func GetDeposits(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetDeposits(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryDeposits(req.Pagination)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, deposittypes.NewDepositsFromRaw(result))
+	}
+}
+
+func GetNode(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetNode(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		nodeAddr, err := hubtypes.NodeAddressFromBech32(req.Address)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryNode(nodeAddr)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1004, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, newNodes(nodetypes.Nodes{*result}, req.WithInfo)[0])
+	}
+}
+
+func GetNodes(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetNodes(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		var (
+			result nodetypes.Nodes
+			qc     = ctx.Query()
+		)
+
+		switch {
+		case req.Plan != 0:
+			result, err = qc.QueryNodesForPlan(req.Plan, req.Pagination)
+		case req.Provider != "":
+			var provAddr hubtypes.ProvAddress
+			provAddr, err = hubtypes.ProvAddressFromBech32(req.Provider)
+			if err == nil {
+				result, err = qc.QueryNodesForProvider(provAddr, hubtypes.StatusFromString(req.Status), req.Pagination)
+			}
+		default:
+			result, err = qc.QueryNodes(hubtypes.StatusFromString(req.Status), req.Pagination)
+		}
+
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, newNodes(result, req.WithInfo))
+	}
+}
+
+func GetPlan(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetPlan(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryPlan(req.ID)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, plantypes.NewPlanFromRaw(result))
+	}
+}
+
+func GetPlans(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetPlans(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		var (
+			result hubplantypes.Plans
+			qc     = ctx.Query()
+		)
+
+		if req.Provider != "" {
+			var provAddr hubtypes.ProvAddress
+			provAddr, err = hubtypes.ProvAddressFromBech32(req.Provider)
+			if err == nil {
+				result, err = qc.QueryPlansForProvider(provAddr, hubtypes.StatusFromString(req.Status), req.Pagination)
+			}
+		} else {
+			result, err = qc.QueryPlans(hubtypes.StatusFromString(req.Status), req.Pagination)
+		}
+
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, plantypes.NewPlansFromRaw(result))
+	}
+}
+
+func GetProvider(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetProvider(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		provAddr, err := hubtypes.ProvAddressFromBech32(req.Address)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryProvider(provAddr)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1004, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, providertypes.NewProviderFromRaw(result))
+	}
+}
+
+func GetProviders(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetProviders(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryProviders(req.Pagination)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, providertypes.NewProvidersFromRaw(result))
+	}
+}
+
+func GetQuota(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetQuota(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		accAddr, err := sdk.AccAddressFromBech32(req.Address)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryQuota(req.ID, accAddr)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1004, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, subscriptiontypes.NewQuotaFromRaw(result))
+	}
+}
+
+func GetQuotas(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetQuotas(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QueryQuotas(req.ID, req.Pagination)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, subscriptiontypes.NewQuotasFromRaw(result))
+	}
+}
+
+func GetSession(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetSession(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QuerySession(req.ID)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, sessiontypes.NewSessionFromRaw(result))
+	}
+}
+
+func GetSessions(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetSessions(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		var (
+			result hubsessiontypes.Sessions
+			qc     = ctx.Query()
+		)
+
+		if req.Address != "" {
+			var accAddr sdk.AccAddress
+			accAddr, err = sdk.AccAddressFromBech32(req.Address)
+			if err == nil {
+				result, err = qc.QuerySessionsForAddress(accAddr, hubtypes.StatusFromString(req.Status), req.Pagination)
+			}
+		} else {
+			result, err = qc.QuerySessions(req.Pagination)
+		}
+
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, sessiontypes.NewSessionsFromRaw(result))
+	}
+}
+
+func GetSubscription(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetSubscription(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		result, err := ctx.Query().QuerySubscription(req.ID)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, subscriptiontypes.NewSubscriptionFromRaw(result))
+	}
+}
+
+func GetSubscriptions(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetSubscriptions(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
+			)
+			return
+		}
+
+		var (
+			result hubsubscriptiontypes.Subscriptions
+			qc     = ctx.Query()
+		)
+
+		if req.Address != "" {
+			var accAddr sdk.AccAddress
+			accAddr, err = sdk.AccAddressFromBech32(req.Address)
+			if err == nil {
+				result, err = qc.QuerySubscriptionsForAddress(accAddr, hubtypes.StatusFromString(req.Status), req.Pagination)
+			}
+		} else {
+			result, err = qc.QuerySubscriptions(req.Pagination)
+		}
+
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, subscriptiontypes.NewSubscriptionsFromRaw(result))
+	}
+}
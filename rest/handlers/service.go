@@ -1,29 +1,139 @@
 package handlers
 
 import (
-	"encoding/binary"
-	"fmt"
-	"net"
+	"bytes"
+	"encoding/json"
 	"net/http"
-	"os"
-	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/sentinel-official/cli-client/context"
 	"github.com/sentinel-official/cli-client/rest/requests"
 	"github.com/sentinel-official/cli-client/rest/responses"
-	"github.com/sentinel-official/cli-client/services/wireguard"
-	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	"github.com/sentinel-official/cli-client/services/killswitch"
 	clitypes "github.com/sentinel-official/cli-client/types"
 	cliutils "github.com/sentinel-official/cli-client/utils"
 )
 
-func Connect(ctx *context.ServerContext) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var (
-			status         = clitypes.NewServiceStatus()
-			statusFilePath = filepath.Join(ctx.Home(), clitypes.StatusFilename)
+type stateRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func newStateRecorder(w http.ResponseWriter) *stateRecorder {
+	return &stateRecorder{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+	}
+}
+
+func (r *stateRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *stateRecorder) Write(p []byte) (int, error) {
+	if r.status != http.StatusOK {
+		r.body.Write(p)
+	}
+
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *stateRecorder) Err() error {
+	if r.status == http.StatusOK {
+		return nil
+	}
+
+	var body clitypes.RestResponseBody
+	if err := json.Unmarshal(r.body.Bytes(), &body); err != nil || body.Error == nil {
+		return errors.New(http.StatusText(r.status))
+	}
+
+	return errors.New(body.Error.Message)
+}
+
+func (r *stateRecorder) Publish(ctx *context.ServerContext, name, state string) {
+	if err := r.Err(); err != nil {
+		ctx.Broker().Publish(clitypes.NewStateEvent(name, clitypes.StateError, err))
+		return
+	}
+
+	ctx.Broker().Publish(clitypes.NewStateEvent(name, state, nil))
+}
+
+func isServiceUp(ctx *context.ServerContext, status *clitypes.ServiceStatus) bool {
+	service, err := ctx.Service(status)
+	return err == nil && service.IsUp()
+}
+
+func newGetStatus(ctx *context.ServerContext, status *clitypes.ServiceStatus) (*responses.GetStatus, error) {
+	service, err := ctx.Service(status)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res = &responses.GetStatus{
+			Name:       status.Name,
+			Type:       status.Type,
+			ID:         status.ID,
+			IFace:      status.IFace,
+			Proxy:      status.Proxy,
+			Namespace:  status.Namespace,
+			KillSwitch: status.KillSwitch && killswitch.NewKillSwitch().IsEnabled(),
+			Reconnect:  ctx.Supervisor().Status(status.Name),
+			Limit:      ctx.Limiter().Status(status.Name),
+		}
+	)
+
+	if service.IsUp() {
+		upload, download, err := service.Transfer()
+		if err != nil {
+			return nil, err
+		}
+
+		stats, err := service.Stats()
+		if err != nil {
+			return nil, err
+		}
+
+		res.Up = true
+		res.Upload = upload
+		res.Download = download
+		res.UploadRate, res.DownloadRate = ctx.Broker().Rates(status.Name)
+		res.Endpoint = stats.Endpoint()
+		res.Peers = stats.Peers
+
+		if t := stats.LatestHandshake(); !t.IsZero() {
+			age := time.Since(t).Seconds()
+			res.LatestHandshake, res.HandshakeAge = &t, &age
+		}
+	}
+
+	return res, nil
+}
+
+func writeConnectionError(w http.ResponseWriter, err error) {
+	var e *context.ConnectionError
+	if errors.As(err, &e) {
+		cliutils.WriteErrorToResponseBody(
+			w, e.Status,
+			clitypes.NewRestError(e.Code, e.Err.Error()),
 		)
+		return
+	}
 
+	cliutils.WriteErrorToResponseBody(
+		w, http.StatusInternalServerError,
+		clitypes.NewRestError(0, err.Error()),
+	)
+}
+
+func Connect(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		req, err := requests.NewConnect(r)
 		if err != nil {
 			cliutils.WriteErrorToResponseBody(
@@ -40,131 +150,104 @@ func Connect(ctx *context.ServerContext) http.HandlerFunc {
 			return
 		}
 
-		if err := status.LoadFromPath(statusFilePath); err != nil {
-			cliutils.WriteErrorToResponseBody(
-				w, http.StatusInternalServerError,
-				clitypes.NewRestError(1003, err.Error()),
-			)
+		ctx.LockConnection(req.Name)
+		defer ctx.UnlockConnection(req.Name)
+
+		if err := ctx.Connect(req); err != nil {
+			writeConnectionError(w, err)
 			return
 		}
 
-		if status.IFace != "" {
-			var (
-				service = wireguard.NewWireGuard().
-					WithConfig(
-						&wireguardtypes.Config{
-							Name: status.IFace,
-						},
-					).
-					WithHome(ctx.Home())
-			)
-
-			if service.IsUp() {
-				cliutils.WriteErrorToResponseBody(
-					w, http.StatusBadRequest,
-					clitypes.NewRestError(1004, fmt.Sprintf("service is already running on interface %s", status.IFace)),
-				)
-				return
-			}
-		}
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, nil)
+	}
+}
 
-		listenPort, err := cliutils.GetFreeUDPPort()
+func Disconnect(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewDisconnect(r)
 		if err != nil {
 			cliutils.WriteErrorToResponseBody(
-				w, http.StatusInternalServerError,
+				w, http.StatusBadRequest,
 				clitypes.NewRestError(1007, err.Error()),
 			)
 			return
 		}
-
-		var (
-			wireGuardConfig = &wireguardtypes.Config{
-				Name: wireguardtypes.DefaultInterface,
-				Interface: wireguardtypes.Interface{
-					Addresses: []wireguardtypes.IPNet{
-						{
-							IP:  net.IP(req.Info[0 : 0+4]),
-							Net: 32,
-						},
-						{
-							IP:  net.IP(req.Info[4 : 4+16]),
-							Net: 128,
-						},
-					},
-					ListenPort: listenPort,
-					PrivateKey: *wireguardtypes.NewKey(req.Keys[0]),
-					DNS: append(
-						[]net.IP{net.ParseIP("10.8.0.1")},
-						req.Resolvers...,
-					),
-				},
-				Peers: []wireguardtypes.Peer{
-					{
-						PublicKey: *wireguardtypes.NewKey(req.Info[26 : 26+32]),
-						AllowedIPs: []wireguardtypes.IPNet{
-							{IP: net.ParseIP("0.0.0.0")},
-							{IP: net.ParseIP("::")},
-						},
-						Endpoint: wireguardtypes.Endpoint{
-							Host: net.IP(req.Info[20 : 20+4]).String(),
-							Port: binary.BigEndian.Uint16(req.Info[24 : 24+2]),
-						},
-						PersistentKeepalive: 15,
-					},
-				},
-			}
-
-			service = wireguard.NewWireGuard().
-				WithConfig(wireGuardConfig).
-				WithHome(ctx.Home())
-		)
-
-		status = clitypes.NewServiceStatus().
-			WithID(req.ID).
-			WithIFace(wireGuardConfig.Name)
-
-		if err := status.SaveToPath(statusFilePath); err != nil {
+		if err := req.Validate(); err != nil {
 			cliutils.WriteErrorToResponseBody(
-				w, http.StatusInternalServerError,
+				w, http.StatusBadRequest,
 				clitypes.NewRestError(1008, err.Error()),
 			)
 			return
 		}
 
-		if err := service.PreUp(); err != nil {
+		ctx.LockConnection(req.Name)
+		defer ctx.UnlockConnection(req.Name)
+
+		if err := ctx.Disconnect(req.Name); err != nil {
+			writeConnectionError(w, err)
+			return
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, nil)
+	}
+}
+
+func GetHistory(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.NewGetHistory(r)
+		if err != nil {
 			cliutils.WriteErrorToResponseBody(
-				w, http.StatusInternalServerError,
-				clitypes.NewRestError(1009, err.Error()),
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1001, err.Error()),
 			)
 			return
 		}
-		if err := service.Up(); err != nil {
+		if err := req.Validate(); err != nil {
 			cliutils.WriteErrorToResponseBody(
-				w, http.StatusInternalServerError,
-				clitypes.NewRestError(1010, err.Error()),
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1002, err.Error()),
 			)
 			return
 		}
-		if err := service.PostUp(); err != nil {
+
+		items, err := clitypes.LoadHistoryRecords(ctx.HistoryFilePath())
+		if err != nil {
 			cliutils.WriteErrorToResponseBody(
 				w, http.StatusInternalServerError,
-				clitypes.NewRestError(1011, err.Error()),
+				clitypes.NewRestError(1003, err.Error()),
 			)
 			return
 		}
 
-		cliutils.WriteResultToResponseBody(w, http.StatusOK, nil)
+		items = items.Filter(req.Match)
+		if items == nil {
+			items = clitypes.HistoryRecords{}
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, items)
 	}
 }
 
-func Disconnect(ctx *context.ServerContext) http.HandlerFunc {
+func GetStatus(ctx *context.ServerContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var (
-			status         = clitypes.NewServiceStatus()
-			statusFilePath = filepath.Join(ctx.Home(), clitypes.StatusFilename)
-		)
+		req, err := requests.NewGetStatus(r)
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1003, err.Error()),
+			)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusBadRequest,
+				clitypes.NewRestError(1004, err.Error()),
+			)
+			return
+		}
 
-		if err := status.LoadFromPath(statusFilePath); err != nil {
+		status := clitypes.NewServiceStatus()
+		if err := status.LoadFromPath(ctx.StatusFilePath(req.Name)); err != nil {
 			cliutils.WriteErrorToResponseBody(
 				w, http.StatusInternalServerError,
 				clitypes.NewRestError(1001, err.Error()),
@@ -173,101 +256,117 @@ func Disconnect(ctx *context.ServerContext) http.HandlerFunc {
 		}
 
 		if status.IFace != "" {
-			var (
-				service = wireguard.NewWireGuard().
-					WithConfig(
-						&wireguardtypes.Config{
-							Name: status.IFace,
-						},
-					).
-					WithHome(ctx.Home())
-			)
+			res, err := newGetStatus(ctx, status.WithName(req.Name))
+			if err != nil {
+				cliutils.WriteErrorToResponseBody(
+					w, http.StatusInternalServerError,
+					clitypes.NewRestError(1002, err.Error()),
+				)
+				return
+			}
 
-			if service.IsUp() {
-				if err := service.PreDown(); err != nil {
-					cliutils.WriteErrorToResponseBody(
-						w, http.StatusInternalServerError,
-						clitypes.NewRestError(1002, err.Error()),
-					)
-					return
-				}
-				if err := service.Down(); err != nil {
-					cliutils.WriteErrorToResponseBody(
-						w, http.StatusInternalServerError,
-						clitypes.NewRestError(1003, err.Error()),
-					)
-					return
-				}
-				if err := service.PostDown(); err != nil {
-					cliutils.WriteErrorToResponseBody(
-						w, http.StatusInternalServerError,
-						clitypes.NewRestError(1004, err.Error()),
-					)
-					return
-				}
+			if res.Up || res.KillSwitch || res.Reconnect != nil {
+				cliutils.WriteResultToResponseBody(w, http.StatusOK, res)
+				return
 			}
 		}
 
-		if err := os.Remove(statusFilePath); err != nil {
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, nil)
+	}
+}
+
+func ListConnections(ctx *context.ServerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := clitypes.LoadServiceStatuses(ctx.Home())
+		if err != nil {
 			cliutils.WriteErrorToResponseBody(
 				w, http.StatusInternalServerError,
-				clitypes.NewRestError(1005, err.Error()),
+				clitypes.NewRestError(1001, err.Error()),
 			)
 			return
 		}
 
-		cliutils.WriteResultToResponseBody(w, http.StatusOK, nil)
+		items := make([]*responses.GetStatus, 0, len(statuses))
+		for _, status := range statuses {
+			item, err := newGetStatus(ctx, status)
+			if err != nil {
+				cliutils.WriteErrorToResponseBody(
+					w, http.StatusInternalServerError,
+					clitypes.NewRestError(1002, err.Error()),
+				)
+				return
+			}
+
+			items = append(items, item)
+		}
+
+		cliutils.WriteResultToResponseBody(w, http.StatusOK, items)
 	}
 }
 
-func GetStatus(ctx *context.ServerContext) http.HandlerFunc {
+func StreamStatus(ctx *context.ServerContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var (
-			status         = clitypes.NewServiceStatus()
-			statusFilePath = filepath.Join(ctx.Home(), clitypes.StatusFilename)
-		)
-
-		if err := status.LoadFromPath(statusFilePath); err != nil {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
 			cliutils.WriteErrorToResponseBody(
 				w, http.StatusInternalServerError,
-				clitypes.NewRestError(1001, err.Error()),
+				clitypes.NewRestError(1001, "streaming is not supported"),
 			)
 			return
 		}
 
-		if status.IFace != "" {
-			var (
-				service = wireguard.NewWireGuard().
-					WithConfig(
-						&wireguardtypes.Config{
-							Name: status.IFace,
-						},
-					).
-					WithHome(ctx.Home())
+		statuses, err := clitypes.LoadServiceStatuses(ctx.Home())
+		if err != nil {
+			cliutils.WriteErrorToResponseBody(
+				w, http.StatusInternalServerError,
+				clitypes.NewRestError(1002, err.Error()),
 			)
+			return
+		}
 
-			if service.IsUp() {
-				upload, download, err := service.Transfer()
-				if err != nil {
-					cliutils.WriteErrorToResponseBody(
-						w, http.StatusInternalServerError,
-						clitypes.NewRestError(1002, err.Error()),
-					)
-					return
-				}
+		var (
+			name        = r.URL.Query().Get("name")
+			events, off = ctx.Broker().Subscribe()
+		)
 
-				cliutils.WriteResultToResponseBody(w, http.StatusOK,
-					&responses.GetStatus{
-						ID:       status.ID,
-						IFace:    status.IFace,
-						Upload:   upload,
-						Download: download,
-					},
-				)
+		defer off()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		for _, status := range statuses {
+			if name != "" && status.Name != name {
+				continue
+			}
+
+			state := clitypes.StateDown
+			if isServiceUp(ctx, status) {
+				state = clitypes.StateUp
+			}
+
+			if err := cliutils.WriteEventToResponseBody(w, clitypes.NewStateEvent(status.Name, state, nil)); err != nil {
 				return
 			}
 		}
 
-		cliutils.WriteResultToResponseBody(w, http.StatusOK, nil)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-events:
+				if name != "" && event.Name != name {
+					continue
+				}
+				if err := cliutils.WriteEventToResponseBody(w, event); err != nil {
+					return
+				}
+
+				flusher.Flush()
+			}
+		}
 	}
 }
@@ -0,0 +1,442 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	hubtypes "github.com/sentinel-official/hub/types"
+
+	"github.com/sentinel-official/cli-client/context"
+	restmodules "github.com/sentinel-official/cli-client/rest/modules"
+	"github.com/sentinel-official/cli-client/rest/routes"
+	"github.com/sentinel-official/cli-client/services"
+	"github.com/sentinel-official/cli-client/services/fake"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+const (
+	from  = "sent1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu"
+	iFace = "wgtest0"
+)
+
+type server struct {
+	router   *mux.Router
+	backend  *fake.Backend
+	registry *services.Registry
+	home     string
+}
+
+func newServer(t *testing.T, options ...func(context.ServerContext) context.ServerContext) *server {
+	var (
+		home     = t.TempDir()
+		backend  = fake.NewBackend()
+		registry = services.NewRegistry()
+	)
+
+	if err := backend.Register(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.NewServerContext().
+		WithHome(home).
+		WithRegistry(registry)
+
+	for _, option := range options {
+		ctx = option(ctx)
+	}
+
+	ctx = ctx.WithBroker(context.NewBroker(ctx))
+
+	supervisor := context.NewSupervisor(ctx)
+	limiter := context.NewLimiter(ctx.WithSupervisor(supervisor))
+
+	ctx = ctx.WithSupervisor(supervisor).
+		WithLimiter(limiter)
+
+	router := mux.NewRouter()
+	restmodules.RegisterService(
+		router.PathPrefix(clitypes.APIPathPrefix).Subrouter(),
+		&ctx,
+	)
+
+	return &server{
+		router:   router,
+		backend:  backend,
+		registry: registry,
+		home:     home,
+	}
+}
+
+type response struct {
+	Success bool                       `json:"success"`
+	Error   *clitypes.RestError        `json:"error"`
+	Result  map[string]json.RawMessage `json:"result"`
+}
+
+func (s *server) post(t *testing.T, route string, body interface{}) (int, *response, map[string]json.RawMessage) {
+	var buf bytes.Buffer
+	if s, ok := body.(string); ok {
+		buf.WriteString(s)
+	} else if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, clitypes.APIPathPrefix+route, &buf)
+	)
+
+	s.router.ServeHTTP(w, r)
+
+	var (
+		keys map[string]json.RawMessage
+		res  response
+	)
+
+	if err := json.Unmarshal(w.Body.Bytes(), &keys); err != nil {
+		t.Fatalf("invalid response body %q: %s", w.Body.String(), err)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("invalid response body %q: %s", w.Body.String(), err)
+	}
+
+	return w.Code, &res, keys
+}
+
+func info() []byte {
+	v := make([]byte, 58)
+	copy(v[0:4], net.IPv4(10, 8, 0, 2).To4())
+	copy(v[4:20], net.ParseIP("fd00::2"))
+	copy(v[20:24], net.IPv4(192, 0, 2, 1).To4())
+	v[24], v[25] = 0xca, 0x6c
+	for i := 26; i < len(v); i++ {
+		v[i] = byte(i)
+	}
+
+	return v
+}
+
+func connectRequest(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"backend": "test",
+		"name":    name,
+		"id":      7,
+		"from":    from,
+		"to":      hubtypes.NodeAddress(bytes.Repeat([]byte{1}, 20)).String(),
+		"info":    info(),
+		"keys":    [][]byte{bytes.Repeat([]byte{2}, 32)},
+		"iface":   iFace,
+	}
+}
+
+func expectError(t *testing.T, code int, res *response, status, errCode int) {
+	t.Helper()
+
+	if code != status {
+		t.Fatalf("expected status %d, got %d", status, code)
+	}
+	if res.Success {
+		t.Fatal("expected success false")
+	}
+	if res.Error == nil || res.Error.Code != errCode {
+		t.Fatalf("expected error code %d, got %+v", errCode, res.Error)
+	}
+	if res.Error.Message == "" {
+		t.Fatal("expected a non-empty error message")
+	}
+}
+
+func expectSuccess(t *testing.T, code int, res *response, keys map[string]json.RawMessage) {
+	t.Helper()
+
+	if code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d with %+v", http.StatusOK, code, res.Error)
+	}
+	if !res.Success || res.Error != nil {
+		t.Fatalf("expected success, got %+v", res.Error)
+	}
+	if _, ok := keys["error"]; ok {
+		t.Fatal("expected no error key")
+	}
+}
+
+func TestConnect(t *testing.T) {
+	s := newServer(t)
+
+	code, res, keys := s.post(t, routes.Connect, connectRequest("default"))
+	expectSuccess(t, code, res, keys)
+
+	if _, ok := keys["result"]; ok {
+		t.Fatal("expected no result key")
+	}
+	if !s.backend.IsUp(iFace) {
+		t.Fatalf("expected device %s to be up", iFace)
+	}
+
+	status := clitypes.NewServiceStatus()
+	if err := status.LoadFromPath(clitypes.ServiceStatusFilePath(s.home, "default")); err != nil {
+		t.Fatal(err)
+	}
+	if status.IFace != iFace || status.ID != 7 || status.Backend != fake.Name || status.From != from {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	code, res, _ = s.post(t, routes.Connect, connectRequest("default"))
+	expectError(t, code, res, http.StatusBadRequest, 1004)
+}
+
+func TestConnectInvalidRequest(t *testing.T) {
+	s := newServer(t)
+
+	code, res, _ := s.post(t, routes.Connect, "{")
+	expectError(t, code, res, http.StatusBadRequest, 1001)
+
+	req := connectRequest("default")
+	delete(req, "id")
+
+	code, res, _ = s.post(t, routes.Connect, req)
+	expectError(t, code, res, http.StatusBadRequest, 1002)
+
+	req = connectRequest("default")
+	req["keys"] = [][]byte{{1}}
+
+	code, res, _ = s.post(t, routes.Connect, req)
+	expectError(t, code, res, http.StatusBadRequest, 1002)
+
+	req = connectRequest("default")
+	req["service_backend"] = "bogus"
+
+	code, res, _ = s.post(t, routes.Connect, req)
+	expectError(t, code, res, http.StatusBadRequest, 1017)
+
+	if names := s.backend.Names(); len(names) != 0 {
+		t.Fatalf("expected no devices, got %v", names)
+	}
+}
+
+func TestConnectToNodeInvalidRequest(t *testing.T) {
+	s := newServer(t)
+
+	code, res, _ := s.post(t, routes.ConnectToNode, "{")
+	expectError(t, code, res, http.StatusBadRequest, 1001)
+
+	req := map[string]interface{}{
+		"backend":      "test",
+		"name":         "default",
+		"subscription": 1,
+		"from":         from,
+		"node":         hubtypes.NodeAddress(bytes.Repeat([]byte{1}, 20)).String(),
+		"mode":         clitypes.ServiceModeTunnel,
+	}
+
+	code, res, _ = s.post(t, routes.ConnectToNode, req)
+	expectError(t, code, res, http.StatusBadRequest, 1002)
+
+	req["tx"] = map[string]interface{}{"chain_id": "sentinelhub-2"}
+	req["node"] = from
+
+	code, res, _ = s.post(t, routes.ConnectToNode, req)
+	expectError(t, code, res, http.StatusBadRequest, 1002)
+
+	if names := s.backend.Names(); len(names) != 0 {
+		t.Fatalf("expected no devices, got %v", names)
+	}
+}
+
+func TestConnectFailure(t *testing.T) {
+	tests := []struct {
+		op     string
+		status int
+		code   int
+	}{
+		{fake.OpNew, http.StatusInternalServerError, 1018},
+		{fake.OpPreUp, http.StatusInternalServerError, 1009},
+		{fake.OpUp, http.StatusInternalServerError, 1010},
+		{fake.OpPostUp, http.StatusInternalServerError, 1011},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			s := newServer(t)
+			s.backend.Fail(tt.op, errors.New("injected"))
+
+			code, res, _ := s.post(t, routes.Connect, connectRequest("default"))
+			expectError(t, code, res, tt.status, tt.code)
+
+			if names := s.backend.Names(); len(names) != 0 {
+				t.Fatalf("expected no devices, got %v", names)
+			}
+			if _, err := os.Stat(clitypes.ServiceStatusFilePath(s.home, "default")); !os.IsNotExist(err) {
+				t.Fatalf("expected the status file to be removed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestConnectNamespace(t *testing.T) {
+	s := newServer(t)
+	s.registry.Register(clitypes.ServiceTypeWireGuard, clitypes.ServiceBackendNamespace, s.backend.Factory)
+
+	req := connectRequest("default")
+	req["mode"] = clitypes.ServiceModeNamespace
+	req["include"] = []string{"10.0.0.0/8"}
+
+	code, res, _ := s.post(t, routes.Connect, req)
+	expectError(t, code, res, http.StatusBadRequest, 1002)
+
+	delete(req, "include")
+
+	code, res, keys := s.post(t, routes.Connect, req)
+	expectSuccess(t, code, res, keys)
+
+	code, res, keys = s.post(t, routes.GetStatus, map[string]string{"name": "default"})
+	expectSuccess(t, code, res, keys)
+
+	if v := string(res.Result["namespace"]); v != `"sentinel-default"` {
+		t.Fatalf("expected namespace sentinel-default, got %s", v)
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	s := newServer(t)
+
+	code, res, keys := s.post(t, routes.GetStatus, map[string]string{"name": "default"})
+	expectSuccess(t, code, res, keys)
+	if _, ok := keys["result"]; ok {
+		t.Fatal("expected no result key without a connection")
+	}
+
+	code, res, keys = s.post(t, routes.Connect, connectRequest("default"))
+	expectSuccess(t, code, res, keys)
+
+	if err := s.backend.SetTransfer(iFace, 1024, 2048); err != nil {
+		t.Fatal(err)
+	}
+
+	code, res, keys = s.post(t, routes.GetStatus, map[string]string{"name": "default"})
+	expectSuccess(t, code, res, keys)
+
+	expected := map[string]string{
+		"name":        `"default"`,
+		"type":        `1`,
+		"up":          `true`,
+		"id":          `7`,
+		"iface":       `"` + iFace + `"`,
+		"upload":      `1024`,
+		"download":    `2048`,
+		"kill_switch": `false`,
+		"endpoint":    `"192.0.2.1:51820"`,
+	}
+
+	for key, value := range expected {
+		if v, ok := res.Result[key]; !ok || string(v) != value {
+			t.Errorf("expected %s to be %s, got %s", key, value, v)
+		}
+	}
+
+	if _, ok := res.Result["reconnect"]; !ok {
+		t.Error("expected the reconnect key")
+	}
+	for _, key := range []string{"upload_rate", "download_rate", "latest_handshake", "handshake_age"} {
+		if _, ok := res.Result[key]; !ok {
+			t.Errorf("expected the %s key", key)
+		}
+	}
+
+	var peers []clitypes.PeerStats
+	if err := json.Unmarshal(res.Result["peers"], &peers); err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[0].RxBytes != 1024 || peers[0].TxBytes != 2048 || peers[0].LatestHandshake.IsZero() {
+		t.Fatalf("unexpected peers %+v", peers)
+	}
+
+	if err := s.backend.SetLatestHandshake(iFace, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	code, res, keys = s.post(t, routes.GetStatus, map[string]string{"name": "default"})
+	expectSuccess(t, code, res, keys)
+	for _, key := range []string{"latest_handshake", "handshake_age"} {
+		if _, ok := res.Result[key]; ok {
+			t.Errorf("expected no %s key without a handshake", key)
+		}
+	}
+	for _, key := range []string{"proxy", "limit"} {
+		if _, ok := res.Result[key]; ok {
+			t.Errorf("expected no %s key", key)
+		}
+	}
+
+	s.backend.Fail(fake.OpTransfer, errors.New("injected"))
+
+	code, res, _ = s.post(t, routes.GetStatus, map[string]string{"name": "default"})
+	expectError(t, code, res, http.StatusInternalServerError, 1002)
+
+	code, res, _ = s.post(t, routes.GetStatus, map[string]string{"name": "-"})
+	expectError(t, code, res, http.StatusBadRequest, 1004)
+}
+
+func TestDisconnect(t *testing.T) {
+	s := newServer(t)
+
+	code, res, keys := s.post(t, routes.Connect, connectRequest("default"))
+	expectSuccess(t, code, res, keys)
+
+	code, res, keys = s.post(t, routes.Disconnect, map[string]string{"name": "default"})
+	expectSuccess(t, code, res, keys)
+
+	if s.backend.IsUp(iFace) {
+		t.Fatalf("expected device %s to be down", iFace)
+	}
+	if _, err := os.Stat(clitypes.ServiceStatusFilePath(s.home, "default")); !os.IsNotExist(err) {
+		t.Fatalf("expected the status file to be removed, got %v", err)
+	}
+
+	code, res, keys = s.post(t, routes.GetStatus, map[string]string{"name": "default"})
+	expectSuccess(t, code, res, keys)
+	if _, ok := keys["result"]; ok {
+		t.Fatal("expected no result key after disconnect")
+	}
+
+	code, res, _ = s.post(t, routes.Disconnect, "{")
+	expectError(t, code, res, http.StatusBadRequest, 1007)
+}
+
+func TestDisconnectFailure(t *testing.T) {
+	tests := []struct {
+		op   string
+		code int
+	}{
+		{fake.OpNew, 1011},
+		{fake.OpPreDown, 1002},
+		{fake.OpDown, 1003},
+		{fake.OpPostDown, 1004},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			s := newServer(t)
+
+			code, res, keys := s.post(t, routes.Connect, connectRequest("default"))
+			expectSuccess(t, code, res, keys)
+
+			s.backend.Fail(tt.op, errors.New("injected"))
+
+			code, res, _ = s.post(t, routes.Disconnect, map[string]string{"name": "default"})
+			expectError(t, code, res, http.StatusInternalServerError, tt.code)
+
+			if _, err := os.Stat(clitypes.ServiceStatusFilePath(s.home, "default")); err != nil {
+				t.Fatalf("expected the status file to be kept, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+	cliutils "github.com/sentinel-official/cli-client/utils"
+)
+
+func IsAllowedOrigin(origins []string, origin string) bool {
+	for _, v := range origins {
+		if strings.EqualFold(strings.TrimSuffix(v, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAllowedHost(hosts []string, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if net.ParseIP(host) != nil || strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	for _, v := range hosts {
+		if strings.EqualFold(v, host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type peerUIDKey struct{}
+
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	conn, ok := c.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+
+	uid, err := cliutils.PeerUID(conn)
+	if err != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, peerUIDKey{}, uid)
+}
+
+func isAllowedUID(uids map[string][]uint32, r *http.Request) bool {
+	uid, ok := r.Context().Value(peerUIDKey{}).(uint32)
+	if !ok {
+		return false
+	}
+	if uid == uint32(os.Getuid()) {
+		return true
+	}
+
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+
+	module, _, _ := strings.Cut(strings.TrimPrefix(route.GetName(), "/"), ".")
+	for _, v := range uids[module] {
+		if v == uid {
+			return true
+		}
+	}
+
+	return false
+}
+
+func Authenticate(token string, uids map[string][]uint32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				v, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if ok && subtle.ConstantTimeCompare([]byte(v), []byte(token)) == 1 {
+					next.ServeHTTP(w, r)
+					return
+				}
+				if isAllowedUID(uids, r) {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				if uid, ok := r.Context().Value(peerUIDKey{}).(uint32); ok {
+					cliutils.WriteErrorToResponseBody(w, http.StatusForbidden, clitypes.NewRestError(1004, fmt.Sprintf("uid %d is not allowed", uid)))
+					return
+				}
+
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				cliutils.WriteErrorToResponseBody(w, http.StatusUnauthorized, clitypes.NewRestError(1001, "missing or invalid bearer token"))
+			},
+		)
+	}
+}
+
+func CheckHost(hosts []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if !isAllowedHost(hosts, r.Host) {
+					cliutils.WriteErrorToResponseBody(w, http.StatusForbidden, clitypes.NewRestError(1002, fmt.Sprintf("host %s is not allowed", r.Host)))
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+func CheckOrigin(origins []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				origin := r.Header.Get("Origin")
+				if origin != "" && !IsAllowedOrigin(origins, origin) {
+					cliutils.WriteErrorToResponseBody(w, http.StatusForbidden, clitypes.NewRestError(1003, fmt.Sprintf("origin %s is not allowed", origin)))
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
@@ -0,0 +1,85 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	restmiddlewares "github.com/sentinel-official/cli-client/rest/middlewares"
+)
+
+func serve(h func(http.Handler) http.Handler, r *http.Request) int {
+	w := httptest.NewRecorder()
+	h(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })).ServeHTTP(w, r)
+
+	return w.Code
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		header string
+		code   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/Service.GetStatus", nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+
+		if code := serve(restmiddlewares.Authenticate("secret", nil), r); code != tt.code {
+			t.Errorf("header %q: got %d, want %d", tt.header, code, tt.code)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host string
+		code int
+	}{
+		{"127.0.0.1:11112", http.StatusOK},
+		{"[::1]:11112", http.StatusOK},
+		{"localhost:11112", http.StatusOK},
+		{"sentinel.local:11112", http.StatusOK},
+		{"attacker.example:11112", http.StatusForbidden},
+		{"attacker.example", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = tt.host
+
+		if code := serve(restmiddlewares.CheckHost([]string{"sentinel.local"}), r); code != tt.code {
+			t.Errorf("host %q: got %d, want %d", tt.host, code, tt.code)
+		}
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		origin string
+		code   int
+	}{
+		{"", http.StatusOK},
+		{"http://localhost:3000", http.StatusOK},
+		{"http://attacker.example", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+
+		if code := serve(restmiddlewares.CheckOrigin([]string{"http://localhost:3000/"}), r); code != tt.code {
+			t.Errorf("origin %q: got %d, want %d", tt.origin, code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package modules
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sentinel-official/cli-client/context"
+	"github.com/sentinel-official/cli-client/rest/handlers"
+	"github.com/sentinel-official/cli-client/rest/routes"
+)
+
+func RegisterQuery(r *mux.Router, ctx *context.ServerContext) {
+	r.Name(routes.GetActiveSession).
+		Methods(http.MethodPost).Path(routes.GetActiveSession).
+		HandlerFunc(handlers.GetActiveSession(ctx))
+	r.Name(routes.GetDeposit).
+		Methods(http.MethodPost).Path(routes.GetDeposit).
+		HandlerFunc(handlers.GetDeposit(ctx))
+	r.Name(routes.GetDeposits).
+		Methods(http.MethodPost).Path(routes.GetDeposits).
+		HandlerFunc(handlers.GetDeposits(ctx))
+	r.Name(routes.GetNode).
+		Methods(http.MethodPost).Path(routes.GetNode).
+		HandlerFunc(handlers.GetNode(ctx))
+	r.Name(routes.GetNodes).
+		Methods(http.MethodPost).Path(routes.GetNodes).
+		HandlerFunc(handlers.GetNodes(ctx))
+	r.Name(routes.GetPlan).
+		Methods(http.MethodPost).Path(routes.GetPlan).
+		HandlerFunc(handlers.GetPlan(ctx))
+	r.Name(routes.GetPlans).
+		Methods(http.MethodPost).Path(routes.GetPlans).
+		HandlerFunc(handlers.GetPlans(ctx))
+	r.Name(routes.GetProvider).
+		Methods(http.MethodPost).Path(routes.GetProvider).
+		HandlerFunc(handlers.GetProvider(ctx))
+	r.Name(routes.GetProviders).
+		Methods(http.MethodPost).Path(routes.GetProviders).
+		HandlerFunc(handlers.GetProviders(ctx))
+	r.Name(routes.GetQuota).
+		Methods(http.MethodPost).Path(routes.GetQuota).
+		HandlerFunc(handlers.GetQuota(ctx))
+	r.Name(routes.GetQuotas).
+		Methods(http.MethodPost).Path(routes.GetQuotas).
+		HandlerFunc(handlers.GetQuotas(ctx))
+	r.Name(routes.GetSession).
+		Methods(http.MethodPost).Path(routes.GetSession).
+		HandlerFunc(handlers.GetSession(ctx))
+	r.Name(routes.GetSessions).
+		Methods(http.MethodPost).Path(routes.GetSessions).
+		HandlerFunc(handlers.GetSessions(ctx))
+	r.Name(routes.GetSubscription).
+		Methods(http.MethodPost).Path(routes.GetSubscription).
+		HandlerFunc(handlers.GetSubscription(ctx))
+	r.Name(routes.GetSubscriptions).
+		Methods(http.MethodPost).Path(routes.GetSubscriptions).
+		HandlerFunc(handlers.GetSubscriptions(ctx))
+}
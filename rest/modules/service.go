@@ -14,12 +14,30 @@ func RegisterService(r *mux.Router, ctx *context.ServerContext) *mux.Router {
 	r.Name(routes.Connect).
 		Methods(http.MethodPost).Path(routes.Connect).
 		Handler(handlers.Connect(ctx))
+	r.Name(routes.ConnectToNode).
+		Methods(http.MethodPost).Path(routes.ConnectToNode).
+		Handler(handlers.ConnectToNode(ctx))
 	r.Name(routes.Disconnect).
 		Methods(http.MethodPost).Path(routes.Disconnect).
 		Handler(handlers.Disconnect(ctx))
+	r.Name(routes.ExportConfig).
+		Methods(http.MethodPost).Path(routes.ExportConfig).
+		Handler(handlers.ExportConfig(ctx))
+	r.Name(routes.GetHistory).
+		Methods(http.MethodPost).Path(routes.GetHistory).
+		Handler(handlers.GetHistory(ctx))
 	r.Name(routes.GetStatus).
 		Methods(http.MethodPost).Path(routes.GetStatus).
 		Handler(handlers.GetStatus(ctx))
+	r.Name(routes.ImportConfig).
+		Methods(http.MethodPost).Path(routes.ImportConfig).
+		Handler(handlers.ImportConfig(ctx))
+	r.Name(routes.ListConnections).
+		Methods(http.MethodPost).Path(routes.ListConnections).
+		Handler(handlers.ListConnections(ctx))
+	r.Name(routes.StreamStatus).
+		Methods(http.MethodGet, http.MethodPost).Path(routes.StreamStatus).
+		Handler(handlers.StreamStatus(ctx))
 
 	return r
 }
@@ -0,0 +1,409 @@
+package requests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/pkg/errors"
+	hubtypes "github.com/sentinel-official/hub/types"
+)
+
+const (
+	MaxNodesWithInfo = 100
+)
+
+func validateStatus(v string) error {
+	if v == "" {
+		return nil
+	}
+	if !hubtypes.StatusFromString(v).IsValid() {
+		return errors.New("status must be either Active, InactivePending or Inactive")
+	}
+
+	return nil
+}
+
+type GetActiveSession struct {
+	Address string `json:"address"`
+}
+
+func NewGetActiveSession(r *http.Request) (*GetActiveSession, error) {
+	var v GetActiveSession
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetActiveSession) Validate() error {
+	if r.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if _, err := sdk.AccAddressFromBech32(r.Address); err != nil {
+		return errors.Wrap(err, "invalid address")
+	}
+
+	return nil
+}
+
+type GetDeposit struct {
+	Address string `json:"address"`
+}
+
+func NewGetDeposit(r *http.Request) (*GetDeposit, error) {
+	var v GetDeposit
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetDeposit) Validate() error {
+	if r.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if _, err := sdk.AccAddressFromBech32(r.Address); err != nil {
+		return errors.Wrap(err, "invalid address")
+	}
+
+	return nil
+}
+
+type GetDeposits struct {
+	Pagination *query.PageRequest `json:"pagination"`
+}
+
+func NewGetDeposits(r *http.Request) (*GetDeposits, error) {
+	var v GetDeposits
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetDeposits) Validate() error {
+	return nil
+}
+
+type GetNode struct {
+	Address  string `json:"address"`
+	WithInfo bool   `json:"with_info"`
+}
+
+func NewGetNode(r *http.Request) (*GetNode, error) {
+	var v GetNode
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetNode) Validate() error {
+	if r.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if _, err := hubtypes.NodeAddressFromBech32(r.Address); err != nil {
+		return errors.Wrap(err, "invalid address")
+	}
+
+	return nil
+}
+
+type GetNodes struct {
+	Provider   string             `json:"provider,omitempty"`
+	Plan       uint64             `json:"plan,omitempty"`
+	Status     string             `json:"status,omitempty"`
+	WithInfo   bool               `json:"with_info"`
+	Pagination *query.PageRequest `json:"pagination"`
+}
+
+func NewGetNodes(r *http.Request) (*GetNodes, error) {
+	var v GetNodes
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if v.WithInfo {
+		if v.Pagination == nil {
+			v.Pagination = &query.PageRequest{}
+		}
+		if v.Pagination.Limit == 0 {
+			v.Pagination.Limit = MaxNodesWithInfo
+		}
+	}
+
+	return &v, nil
+}
+
+func (r *GetNodes) Validate() error {
+	if r.Provider != "" {
+		if _, err := hubtypes.ProvAddressFromBech32(r.Provider); err != nil {
+			return errors.Wrap(err, "invalid provider")
+		}
+	}
+	if r.Provider != "" && r.Plan != 0 {
+		return errors.New("provider and plan cannot be used together")
+	}
+	if r.Plan != 0 && r.Status != "" {
+		return errors.New("status cannot be used with plan")
+	}
+	if err := validateStatus(r.Status); err != nil {
+		return err
+	}
+	if r.WithInfo && r.Pagination != nil && r.Pagination.Limit > MaxNodesWithInfo {
+		return fmt.Errorf("pagination limit cannot be greater than %d with with_info", MaxNodesWithInfo)
+	}
+
+	return nil
+}
+
+type GetPlan struct {
+	ID uint64 `json:"id"`
+}
+
+func NewGetPlan(r *http.Request) (*GetPlan, error) {
+	var v GetPlan
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetPlan) Validate() error {
+	if r.ID == 0 {
+		return errors.New("id cannot be 0")
+	}
+
+	return nil
+}
+
+type GetPlans struct {
+	Provider   string             `json:"provider,omitempty"`
+	Status     string             `json:"status,omitempty"`
+	Pagination *query.PageRequest `json:"pagination"`
+}
+
+func NewGetPlans(r *http.Request) (*GetPlans, error) {
+	var v GetPlans
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetPlans) Validate() error {
+	if r.Provider != "" {
+		if _, err := hubtypes.ProvAddressFromBech32(r.Provider); err != nil {
+			return errors.Wrap(err, "invalid provider")
+		}
+	}
+	if err := validateStatus(r.Status); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type GetProvider struct {
+	Address string `json:"address"`
+}
+
+func NewGetProvider(r *http.Request) (*GetProvider, error) {
+	var v GetProvider
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetProvider) Validate() error {
+	if r.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if _, err := hubtypes.ProvAddressFromBech32(r.Address); err != nil {
+		return errors.Wrap(err, "invalid address")
+	}
+
+	return nil
+}
+
+type GetProviders struct {
+	Pagination *query.PageRequest `json:"pagination"`
+}
+
+func NewGetProviders(r *http.Request) (*GetProviders, error) {
+	var v GetProviders
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetProviders) Validate() error {
+	return nil
+}
+
+type GetQuota struct {
+	ID      uint64 `json:"id"`
+	Address string `json:"address"`
+}
+
+func NewGetQuota(r *http.Request) (*GetQuota, error) {
+	var v GetQuota
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetQuota) Validate() error {
+	if r.ID == 0 {
+		return errors.New("id cannot be 0")
+	}
+	if r.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if _, err := sdk.AccAddressFromBech32(r.Address); err != nil {
+		return errors.Wrap(err, "invalid address")
+	}
+
+	return nil
+}
+
+type GetQuotas struct {
+	ID         uint64             `json:"id"`
+	Pagination *query.PageRequest `json:"pagination"`
+}
+
+func NewGetQuotas(r *http.Request) (*GetQuotas, error) {
+	var v GetQuotas
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetQuotas) Validate() error {
+	if r.ID == 0 {
+		return errors.New("id cannot be 0")
+	}
+
+	return nil
+}
+
+type GetSession struct {
+	ID uint64 `json:"id"`
+}
+
+func NewGetSession(r *http.Request) (*GetSession, error) {
+	var v GetSession
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetSession) Validate() error {
+	if r.ID == 0 {
+		return errors.New("id cannot be 0")
+	}
+
+	return nil
+}
+
+type GetSessions struct {
+	Address    string             `json:"address,omitempty"`
+	Status     string             `json:"status,omitempty"`
+	Pagination *query.PageRequest `json:"pagination"`
+}
+
+func NewGetSessions(r *http.Request) (*GetSessions, error) {
+	var v GetSessions
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetSessions) Validate() error {
+	if r.Address != "" {
+		if _, err := sdk.AccAddressFromBech32(r.Address); err != nil {
+			return errors.Wrap(err, "invalid address")
+		}
+	}
+	if r.Address == "" && r.Status != "" {
+		return errors.New("status requires address")
+	}
+	if err := validateStatus(r.Status); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type GetSubscription struct {
+	ID uint64 `json:"id"`
+}
+
+func NewGetSubscription(r *http.Request) (*GetSubscription, error) {
+	var v GetSubscription
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetSubscription) Validate() error {
+	if r.ID == 0 {
+		return errors.New("id cannot be 0")
+	}
+
+	return nil
+}
+
+type GetSubscriptions struct {
+	Address    string             `json:"address,omitempty"`
+	Status     string             `json:"status,omitempty"`
+	Pagination *query.PageRequest `json:"pagination"`
+}
+
+func NewGetSubscriptions(r *http.Request) (*GetSubscriptions, error) {
+	var v GetSubscriptions
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetSubscriptions) Validate() error {
+	if r.Address != "" {
+		if _, err := sdk.AccAddressFromBech32(r.Address); err != nil {
+			return errors.Wrap(err, "invalid address")
+		}
+	}
+	if r.Address == "" && r.Status != "" {
+		return errors.New("status requires address")
+	}
+	if err := validateStatus(r.Status); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package requests_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sentinel-official/cli-client/rest/requests"
+)
+
+func TestGetNodesPagination(t *testing.T) {
+	tests := []struct {
+		body  string
+		limit uint64
+		valid bool
+	}{
+		{`{}`, 0, true},
+		{`{"with_info":true}`, requests.MaxNodesWithInfo, true},
+		{`{"with_info":true,"pagination":{"limit":10}}`, 10, true},
+		{`{"with_info":true,"pagination":{"limit":101}}`, 101, false},
+		{`{"with_info":false,"pagination":{"limit":1000}}`, 1000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			req, err := requests.NewGetNodes(httptest.NewRequest("POST", "/", strings.NewReader(tt.body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var limit uint64
+			if req.Pagination != nil {
+				limit = req.Pagination.Limit
+			}
+			if limit != tt.limit {
+				t.Fatalf("expected limit %d, got %d", tt.limit, limit)
+			}
+
+			err = req.Validate()
+			if tt.valid && err != nil {
+				t.Fatalf("expected valid, got %s", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -2,32 +2,87 @@ package requests
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/pkg/errors"
 	hubtypes "github.com/sentinel-official/hub/types"
+
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
 )
 
+func validateName(v string) error {
+	if v == "" {
+		return errors.New("name cannot be empty")
+	}
+	if !clitypes.ConnectionNameRegexp.MatchString(v) {
+		return errors.New("name must contain only lowercase letters, digits, dashes and underscores")
+	}
+
+	return nil
+}
+
 type Connect struct {
 	Backend  string `json:"backend"`
 	Password string `json:"password"`
 
-	ID   uint64 `json:"id"`
-	From string `json:"from"`
-	To   string `json:"to"`
+	Name string `json:"name"`
+	Type uint64 `json:"type"`
+	Host string `json:"host"`
+
+	ID           uint64 `json:"id"`
+	Subscription uint64 `json:"subscription"`
+	From         string `json:"from"`
+	To           string `json:"to"`
 
-	Info      []byte   `json:"info"`
-	Keys      [][]byte `json:"keys"`
-	Resolvers []net.IP `json:"resolvers"`
+	Info           []byte   `json:"info"`
+	Keys           [][]byte `json:"keys"`
+	Resolvers      []net.IP `json:"resolvers"`
+	NoNodeResolver bool     `json:"no_node_resolver"`
+	Include        []string `json:"include"`
+	Exclude        []string `json:"exclude"`
+
+	KillSwitch bool `json:"kill_switch"`
+
+	Mode           string `json:"mode"`
+	ProxyListen    string `json:"proxy_listen"`
+	ServiceBackend string `json:"service_backend"`
+
+	IFace               string   `json:"iface"`
+	MTU                 uint16   `json:"mtu"`
+	PersistentKeepalive uint16   `json:"persistent_keepalive"`
+	DNSSearch           []string `json:"dns_search"`
+
+	MaxBytes    int64         `json:"max_bytes"`
+	MaxDuration time.Duration `json:"max_duration"`
+	EndSession  bool          `json:"end_session"`
+
+	Tx *Tx `json:"tx,omitempty"`
 }
 
 func NewConnect(r *http.Request) (*Connect, error) {
-	var v Connect
+	v := Connect{
+		PersistentKeepalive: wireguardtypes.DefaultPersistentKeepalive,
+	}
+
 	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
 		return nil, err
 	}
+	if v.Name == "" {
+		v.Name = clitypes.DefaultConnection
+	}
+	if v.Type == 0 {
+		v.Type = clitypes.ServiceTypeWireGuard
+	}
+	if v.Mode == "" {
+		v.Mode = clitypes.ServiceModeTunnel
+	}
 
 	return &v, nil
 }
@@ -48,6 +103,9 @@ func (r *Connect) Validate() error {
 		}
 	}
 
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
 	if r.ID == 0 {
 		return errors.New("id cannot be 0")
 	}
@@ -60,22 +118,497 @@ func (r *Connect) Validate() error {
 	if _, err := hubtypes.NodeAddressFromBech32(r.To); err != nil {
 		return errors.Wrap(err, "invalid to")
 	}
+	if r.Tx != nil {
+		if err := r.Tx.Validate(); err != nil {
+			return errors.Wrap(err, "invalid tx")
+		}
+	}
+	if r.MaxBytes < 0 {
+		return errors.New("max_bytes cannot be negative")
+	}
+	if r.MaxDuration < 0 {
+		return errors.New("max_duration cannot be negative")
+	}
+	if r.EndSession && r.Tx == nil {
+		return errors.New("tx cannot be nil when end_session is set")
+	}
 
 	if r.Info == nil {
 		return errors.New("info cannot be nil")
 	}
-	if len(r.Info) != 58 {
-		return errors.New("info length must be 58 bytes")
-	}
 	if r.Keys == nil {
 		return errors.New("keys cannot be nil")
 	}
 	if len(r.Keys) != 1 {
 		return errors.New("keys length must be 1")
 	}
+	if r.Mode != clitypes.ServiceModeTunnel && r.Mode != clitypes.ServiceModeProxy && r.Mode != clitypes.ServiceModeNamespace {
+		return errors.New("mode must be either tunnel, proxy or namespace")
+	}
+
+	switch r.Type {
+	case clitypes.ServiceTypeWireGuard:
+		return r.validateWireGuard()
+	case clitypes.ServiceTypeV2Ray:
+		return r.validateV2Ray()
+	default:
+		return errors.New("type must be either 1 (wireguard) or 2 (v2ray)")
+	}
+}
+
+func (r *Connect) validateWireGuard() error {
+	if len(r.Info) != 58 {
+		return errors.New("info length must be 58 bytes")
+	}
 	if len(r.Keys[0]) != 32 {
 		return errors.New("key at index 0 length must be 32 bytes")
 	}
+	for _, s := range r.Include {
+		if _, err := wireguardtypes.ParseIPNet(s); err != nil {
+			return errors.Wrapf(err, "invalid include %s", s)
+		}
+	}
+	for _, s := range r.Exclude {
+		if _, err := wireguardtypes.ParseIPNet(s); err != nil {
+			return errors.Wrapf(err, "invalid exclude %s", s)
+		}
+	}
+	if r.IFace != "" && !wireguardtypes.InterfaceNameRegexp.MatchString(r.IFace) {
+		return errors.New("iface must be at most 15 letters, digits or _=+.- characters")
+	}
+	if r.MTU != 0 && (r.MTU < wireguardtypes.MinMTU || r.MTU > wireguardtypes.MaxMTU) {
+		return fmt.Errorf("mtu must be between %d and %d", wireguardtypes.MinMTU, wireguardtypes.MaxMTU)
+	}
+	for _, s := range r.DNSSearch {
+		if !wireguardtypes.DomainRegexp.MatchString(s) {
+			return fmt.Errorf("invalid dns_search %s", s)
+		}
+	}
+
+	if r.Mode == clitypes.ServiceModeProxy {
+		return r.validateProxy()
+	}
+	if r.ProxyListen != "" {
+		return errors.New("proxy_listen is supported only by mode proxy")
+	}
+	if r.ServiceBackend == clitypes.ServiceBackendUserspace {
+		return errors.New("service_backend userspace is supported only by mode proxy")
+	}
+	if r.Mode == clitypes.ServiceModeNamespace {
+		return r.validateNamespace()
+	}
+	if r.ServiceBackend == clitypes.ServiceBackendNamespace {
+		return errors.New("service_backend netns is supported only by mode namespace")
+	}
+
+	return nil
+}
+
+func (r *Connect) validateProxy() error {
+	if r.ProxyListen == "" {
+		return errors.New("proxy_listen cannot be empty")
+	}
+	if err := validateLoopback(r.ProxyListen); err != nil {
+		return errors.Wrap(err, "invalid proxy_listen")
+	}
+	if len(r.Include) > 0 || len(r.Exclude) > 0 {
+		return errors.New("include and exclude are not supported by mode proxy")
+	}
+	if r.KillSwitch {
+		return errors.New("kill_switch is not supported by mode proxy")
+	}
+	if r.IFace != "" || len(r.DNSSearch) > 0 {
+		return errors.New("iface and dns_search are not supported by mode proxy")
+	}
+	if r.ServiceBackend != "" && r.ServiceBackend != clitypes.ServiceBackendUserspace {
+		return errors.New("mode proxy is supported only by service_backend userspace")
+	}
+
+	return nil
+}
+
+func validateLoopback(s string) error {
+	host, _, err := net.SplitHostPort(s)
+	if err != nil {
+		return err
+	}
+	if _, err := net.ResolveTCPAddr("tcp", s); err != nil {
+		return err
+	}
+	if strings.EqualFold(host, "localhost") {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("host %q must be a loopback address", host)
+	}
+
+	return nil
+}
+
+func (r *Connect) validateNamespace() error {
+	if len(r.Include) > 0 || len(r.Exclude) > 0 {
+		return errors.New("include and exclude are not supported by mode namespace")
+	}
+	if r.KillSwitch {
+		return errors.New("kill_switch is not supported by mode namespace")
+	}
+	if r.ServiceBackend != "" && r.ServiceBackend != clitypes.ServiceBackendNamespace {
+		return errors.New("mode namespace is supported only by service_backend netns")
+	}
+
+	return nil
+}
+
+func (r *Connect) validateV2Ray() error {
+	if r.Host == "" {
+		return errors.New("host cannot be empty")
+	}
+	if len(r.Info) != 3 {
+		return errors.New("info length must be 3 bytes")
+	}
+	if len(r.Keys[0]) != 16 {
+		return errors.New("key at index 0 length must be 16 bytes")
+	}
+	if len(r.Include) > 0 || len(r.Exclude) > 0 {
+		return errors.New("include and exclude are not supported by v2ray")
+	}
+	if r.KillSwitch {
+		return errors.New("kill_switch is not supported by v2ray")
+	}
+	if r.IFace != "" || r.MTU != 0 || len(r.DNSSearch) > 0 {
+		return errors.New("iface, mtu and dns_search are not supported by v2ray")
+	}
+	if r.Mode != clitypes.ServiceModeTunnel {
+		return fmt.Errorf("mode %s is not supported by v2ray", r.Mode)
+	}
+	if r.ProxyListen != "" {
+		return errors.New("proxy_listen is not supported by v2ray")
+	}
+	if r.ServiceBackend != "" && r.ServiceBackend != clitypes.ServiceBackendV2Ray {
+		return errors.New("service_backend must be v2ray for v2ray")
+	}
+
+	return nil
+}
+
+type ConnectToNode struct {
+	Backend  string `json:"backend"`
+	Password string `json:"password"`
+
+	Name         string `json:"name"`
+	Subscription uint64 `json:"subscription"`
+	From         string `json:"from"`
+	Node         string `json:"node"`
+	Rating       uint64 `json:"rating"`
+
+	Resolvers      []net.IP `json:"resolvers"`
+	NoNodeResolver bool     `json:"no_node_resolver"`
+	Include        []string `json:"include"`
+	Exclude        []string `json:"exclude"`
+
+	KillSwitch bool `json:"kill_switch"`
+
+	Mode           string `json:"mode"`
+	ProxyListen    string `json:"proxy_listen"`
+	ServiceBackend string `json:"service_backend"`
+
+	IFace               string   `json:"iface"`
+	MTU                 uint16   `json:"mtu"`
+	PersistentKeepalive uint16   `json:"persistent_keepalive"`
+	DNSSearch           []string `json:"dns_search"`
+
+	MaxBytes    int64         `json:"max_bytes"`
+	MaxDuration time.Duration `json:"max_duration"`
+	EndSession  bool          `json:"end_session"`
+
+	Tx *Tx `json:"tx"`
+}
+
+func NewConnectToNode(r *http.Request) (*ConnectToNode, error) {
+	v := ConnectToNode{
+		PersistentKeepalive: wireguardtypes.DefaultPersistentKeepalive,
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+		return nil, err
+	}
+	if v.Name == "" {
+		v.Name = clitypes.DefaultConnection
+	}
+	if v.Mode == "" {
+		v.Mode = clitypes.ServiceModeTunnel
+	}
+
+	return &v, nil
+}
+
+func (r *ConnectToNode) Validate() error {
+	if r.Backend == "" {
+		return errors.New("backend cannot be empty")
+	}
+	if r.Backend != keyring.BackendFile && r.Backend != keyring.BackendOS && r.Backend != keyring.BackendTest {
+		return errors.New("backend must be either file, os, or test")
+	}
+	if r.Backend == keyring.BackendFile {
+		if r.Password == "" {
+			return errors.New("password cannot be empty")
+		}
+		if len(r.Password) < 8 {
+			return errors.New("password length cannot be less than 8 characters")
+		}
+	}
+
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if r.Subscription == 0 {
+		return errors.New("subscription cannot be 0")
+	}
+	if r.From == "" {
+		return errors.New("from cannot be empty")
+	}
+	if r.Node == "" {
+		return errors.New("node cannot be empty")
+	}
+	if _, err := hubtypes.NodeAddressFromBech32(r.Node); err != nil {
+		return errors.Wrap(err, "invalid node")
+	}
+	if r.Rating > 10 {
+		return errors.New("rating cannot be greater than 10")
+	}
+	if r.Tx == nil {
+		return errors.New("tx cannot be nil")
+	}
+	if err := r.Tx.Validate(); err != nil {
+		return errors.Wrap(err, "invalid tx")
+	}
+	if r.MaxBytes < 0 {
+		return errors.New("max_bytes cannot be negative")
+	}
+	if r.MaxDuration < 0 {
+		return errors.New("max_duration cannot be negative")
+	}
+	if _, err := wireguardtypes.ParseIPNets(r.Include); err != nil {
+		return errors.Wrap(err, "invalid include")
+	}
+	if _, err := wireguardtypes.ParseIPNets(r.Exclude); err != nil {
+		return errors.Wrap(err, "invalid exclude")
+	}
+
+	switch r.Mode {
+	case clitypes.ServiceModeTunnel:
+		return nil
+	case clitypes.ServiceModeProxy:
+		return r.Connect(clitypes.ServiceTypeWireGuard, "", 0, "", nil, nil).validateProxy()
+	case clitypes.ServiceModeNamespace:
+		return r.Connect(clitypes.ServiceTypeWireGuard, "", 0, "", nil, nil).validateNamespace()
+	default:
+		return errors.New("mode must be either tunnel, proxy or namespace")
+	}
+}
+
+func (r *ConnectToNode) Connect(t uint64, host string, id uint64, from string, info, key []byte) *Connect {
+	v := &Connect{
+		Backend:             r.Backend,
+		Password:            r.Password,
+		Name:                r.Name,
+		Type:                t,
+		Host:                host,
+		ID:                  id,
+		Subscription:        r.Subscription,
+		From:                from,
+		To:                  r.Node,
+		Info:                info,
+		Keys:                [][]byte{key},
+		Resolvers:           r.Resolvers,
+		NoNodeResolver:      r.NoNodeResolver,
+		Include:             r.Include,
+		Exclude:             r.Exclude,
+		KillSwitch:          r.KillSwitch,
+		Mode:                r.Mode,
+		ProxyListen:         r.ProxyListen,
+		ServiceBackend:      r.ServiceBackend,
+		IFace:               r.IFace,
+		MTU:                 r.MTU,
+		PersistentKeepalive: r.PersistentKeepalive,
+		DNSSearch:           r.DNSSearch,
+		MaxBytes:            r.MaxBytes,
+		MaxDuration:         r.MaxDuration,
+		EndSession:          r.EndSession,
+		Tx:                  r.Tx,
+	}
+
+	if v.Mode != clitypes.ServiceModeProxy {
+		v.ProxyListen = ""
+	}
+	if v.Type == clitypes.ServiceTypeV2Ray {
+		v.IFace, v.MTU, v.DNSSearch, v.ServiceBackend = "", 0, nil, ""
+	}
+	if v.Mode == clitypes.ServiceModeProxy {
+		v.IFace, v.DNSSearch = "", nil
+	}
+
+	return v
+}
+
+type Disconnect struct {
+	Name string `json:"name"`
+}
+
+func NewDisconnect(r *http.Request) (*Disconnect, error) {
+	var v Disconnect
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if v.Name == "" {
+		v.Name = clitypes.DefaultConnection
+	}
+
+	return &v, nil
+}
+
+func (r *Disconnect) Validate() error {
+	return validateName(r.Name)
+}
+
+type GetStatus struct {
+	Name string `json:"name"`
+}
+
+func NewGetStatus(r *http.Request) (*GetStatus, error) {
+	var v GetStatus
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if v.Name == "" {
+		v.Name = clitypes.DefaultConnection
+	}
+
+	return &v, nil
+}
+
+func (r *GetStatus) Validate() error {
+	return validateName(r.Name)
+}
+
+type GetHistory struct {
+	Name   string    `json:"name,omitempty"`
+	Node   string    `json:"node,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+}
+
+func NewGetHistory(r *http.Request) (*GetHistory, error) {
+	var v GetHistory
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *GetHistory) Validate() error {
+	if r.Name != "" {
+		if err := validateName(r.Name); err != nil {
+			return err
+		}
+	}
+	if r.Node != "" {
+		if _, err := hubtypes.NodeAddressFromBech32(r.Node); err != nil {
+			return errors.Wrap(err, "invalid node")
+		}
+	}
+	if r.Reason != "" {
+		switch r.Reason {
+		case clitypes.HistoryReasonCleanup, clitypes.HistoryReasonDisconnect, clitypes.HistoryReasonFailover, clitypes.HistoryReasonLimit:
+		default:
+			return errors.New("reason must be one of cleanup, disconnect, failover or limit")
+		}
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && r.Until.Before(r.Since) {
+		return errors.New("until cannot be before since")
+	}
+
+	return nil
+}
+
+func (r *GetHistory) Match(v clitypes.HistoryRecord) bool {
+	if r.Name != "" && v.Name != r.Name {
+		return false
+	}
+	if r.Node != "" && v.Node != r.Node {
+		return false
+	}
+	if r.Reason != "" && v.Reason != r.Reason {
+		return false
+	}
+	if !r.Since.IsZero() && v.EndAt.Before(r.Since) {
+		return false
+	}
+	if !r.Until.IsZero() && v.StartAt.After(r.Until) {
+		return false
+	}
+
+	return true
+}
+
+type ExportConfig struct {
+	Name string `json:"name"`
+}
+
+func NewExportConfig(r *http.Request) (*ExportConfig, error) {
+	var v ExportConfig
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if v.Name == "" {
+		v.Name = clitypes.DefaultConnection
+	}
+
+	return &v, nil
+}
+
+func (r *ExportConfig) Validate() error {
+	return validateName(r.Name)
+}
+
+type ImportConfig struct {
+	Name       string `json:"name"`
+	Config     string `json:"config"`
+	KillSwitch bool   `json:"kill_switch"`
+}
+
+func NewImportConfig(r *http.Request) (*ImportConfig, error) {
+	var v ImportConfig
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+		return nil, err
+	}
+	if v.Name == "" {
+		v.Name = clitypes.DefaultConnection
+	}
+
+	return &v, nil
+}
+
+func (r *ImportConfig) Validate() error {
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if r.Config == "" {
+		return errors.New("config cannot be empty")
+	}
+
+	cfg, err := wireguardtypes.ParseWgQuick([]byte(r.Config))
+	if err != nil {
+		return errors.Wrap(err, "invalid config")
+	}
+	if len(cfg.Peers) == 0 {
+		return errors.New("config must contain at least one peer")
+	}
+	if cfg.Peers[0].Endpoint.IsEmpty() {
+		return errors.New("endpoint of the first peer cannot be empty")
+	}
 
 	return nil
 }
@@ -0,0 +1,57 @@
+package requests_test
+
+import (
+	"bytes"
+	"testing"
+
+	hubtypes "github.com/sentinel-official/hub/types"
+
+	"github.com/sentinel-official/cli-client/rest/requests"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func TestConnectValidateProxyListen(t *testing.T) {
+	tests := []struct {
+		listen string
+		valid  bool
+	}{
+		{"127.0.0.1:1080", true},
+		{"127.0.0.2:1080", true},
+		{"[::1]:1080", true},
+		{"localhost:1080", true},
+		{"LOCALHOST:0", true},
+		{"", false},
+		{":1080", false},
+		{"0.0.0.0:1080", false},
+		{"[::]:1080", false},
+		{"192.168.1.10:1080", false},
+		{"example.com:1080", false},
+		{"127.0.0.1", false},
+		{"127.0.0.1:http-proxy-x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.listen, func(t *testing.T) {
+			req := &requests.Connect{
+				Backend:     "test",
+				Name:        "default",
+				Type:        clitypes.ServiceTypeWireGuard,
+				ID:          1,
+				From:        "sent1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu",
+				To:          hubtypes.NodeAddress(bytes.Repeat([]byte{1}, 20)).String(),
+				Info:        make([]byte, 58),
+				Keys:        [][]byte{make([]byte, 32)},
+				Mode:        clitypes.ServiceModeProxy,
+				ProxyListen: tt.listen,
+			}
+
+			err := req.Validate()
+			if tt.valid && err != nil {
+				t.Fatalf("expected valid, got %s", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
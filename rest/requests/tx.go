@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+)
+
+type Tx struct {
+	BroadcastMode string `json:"broadcast_mode"`
+	ChainID       string `json:"chain_id"`
+	Gas           uint64 `json:"gas"`
+	GasPrices     string `json:"gas_prices"`
+	Memo          string `json:"memo"`
+	RPCAddress    string `json:"rpc_address"`
+}
+
+func (r *Tx) Validate() error {
+	if r.BroadcastMode != flags.BroadcastAsync && r.BroadcastMode != flags.BroadcastBlock && r.BroadcastMode != flags.BroadcastSync {
+		return errors.New("broadcast_mode must be either async, block, or sync")
+	}
+	if r.ChainID == "" {
+		return errors.New("chain_id cannot be empty")
+	}
+	if r.Gas == 0 {
+		return errors.New("gas cannot be 0")
+	}
+	if _, err := sdk.ParseDecCoins(r.GasPrices); err != nil {
+		return errors.Wrap(err, "invalid gas_prices")
+	}
+	if r.RPCAddress == "" {
+		return errors.New("rpc_address cannot be empty")
+	}
+
+	return nil
+}
@@ -1,8 +1,28 @@
 package responses
 
+import (
+	"time"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
 type GetStatus struct {
-	ID       uint64 `json:"id"`
-	IFace    string `json:"iface"`
-	Upload   int64  `json:"upload"`
-	Download int64  `json:"download"`
+	Name            string                    `json:"name"`
+	Type            uint64                    `json:"type"`
+	Up              bool                      `json:"up"`
+	Proxy           string                    `json:"proxy,omitempty"`
+	Namespace       string                    `json:"namespace,omitempty"`
+	ID              uint64                    `json:"id"`
+	IFace           string                    `json:"iface"`
+	Upload          int64                     `json:"upload"`
+	Download        int64                     `json:"download"`
+	UploadRate      float64                   `json:"upload_rate"`
+	DownloadRate    float64                   `json:"download_rate"`
+	Endpoint        string                    `json:"endpoint,omitempty"`
+	LatestHandshake *time.Time                `json:"latest_handshake,omitempty"`
+	HandshakeAge    *float64                  `json:"handshake_age,omitempty"`
+	Peers           []clitypes.PeerStats      `json:"peers,omitempty"`
+	KillSwitch      bool                      `json:"kill_switch"`
+	Reconnect       *clitypes.ReconnectStatus `json:"reconnect,omitempty"`
+	Limit           *clitypes.LimitStatus     `json:"limit,omitempty"`
 }
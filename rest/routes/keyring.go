@@ -1,6 +1,8 @@
 package routes
 
 const (
+	ModuleKeyring = "Keyring"
+
 	AddKey      = "/Keyring.AddKey"
 	DeleteKey   = "/Keyring.DeleteKey"
 	GetKey      = "/Keyring.GetKey"
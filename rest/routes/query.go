@@ -0,0 +1,21 @@
+package routes
+
+const (
+	ModuleQuery = "Query"
+
+	GetActiveSession = "/Query.GetActiveSession"
+	GetDeposit       = "/Query.GetDeposit"
+	GetDeposits      = "/Query.GetDeposits"
+	GetNode          = "/Query.GetNode"
+	GetNodes         = "/Query.GetNodes"
+	GetPlan          = "/Query.GetPlan"
+	GetPlans         = "/Query.GetPlans"
+	GetProvider      = "/Query.GetProvider"
+	GetProviders     = "/Query.GetProviders"
+	GetQuota         = "/Query.GetQuota"
+	GetQuotas        = "/Query.GetQuotas"
+	GetSession       = "/Query.GetSession"
+	GetSessions      = "/Query.GetSessions"
+	GetSubscription  = "/Query.GetSubscription"
+	GetSubscriptions = "/Query.GetSubscriptions"
+)
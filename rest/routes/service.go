@@ -1,7 +1,15 @@
 package routes
 
 const (
-	Connect    = "/Service.Connect"
-	Disconnect = "/Service.Disconnect"
-	GetStatus  = "/Service.GetStatus"
+	ModuleService = "Service"
+
+	Connect         = "/Service.Connect"
+	ConnectToNode   = "/Service.ConnectToNode"
+	Disconnect      = "/Service.Disconnect"
+	ExportConfig    = "/Service.ExportConfig"
+	GetHistory      = "/Service.GetHistory"
+	GetStatus       = "/Service.GetStatus"
+	ImportConfig    = "/Service.ImportConfig"
+	ListConnections = "/Service.ListConnections"
+	StreamStatus    = "/Service.StreamStatus"
 )
@@ -0,0 +1,251 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sentinel-official/cli-client/services"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+const (
+	Name = "fake"
+
+	OpNew      = "new"
+	OpPreUp    = "pre_up"
+	OpUp       = "up"
+	OpPostUp   = "post_up"
+	OpPreDown  = "pre_down"
+	OpDown     = "down"
+	OpPostDown = "post_down"
+	OpTransfer = "transfer"
+	OpStats    = "stats"
+)
+
+var (
+	_ clitypes.Service = (*Service)(nil)
+)
+
+type device struct {
+	upload    int64
+	download  int64
+	handshake time.Time
+}
+
+type Backend struct {
+	mutex    sync.Mutex
+	devices  map[string]*device
+	failures map[string]error
+}
+
+func NewBackend() *Backend {
+	return &Backend{
+		devices:  make(map[string]*device),
+		failures: make(map[string]error),
+	}
+}
+
+func (b *Backend) Factory(_ string, status *clitypes.ServiceStatus, _ interface{}) (clitypes.Service, error) {
+	if err := b.failure(status.IFace, OpNew); err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		backend:  b,
+		name:     status.IFace,
+		endpoint: status.Endpoint,
+	}, nil
+}
+
+func (b *Backend) Register(r *services.Registry) error {
+	for _, t := range []uint64{clitypes.ServiceTypeWireGuard, clitypes.ServiceTypeV2Ray} {
+		if err := r.Register(t, Name, b.Factory).SetDefault(t, Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Backend) Fail(op string, err error) *Backend {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err == nil {
+		delete(b.failures, op)
+	} else {
+		b.failures[op] = err
+	}
+
+	return b
+}
+
+func (b *Backend) failure(name, op string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err, ok := b.failures[op]; ok {
+		return fmt.Errorf("%s of device %s failed: %w", op, name, err)
+	}
+
+	return nil
+}
+
+func (b *Backend) SetTransfer(name string, upload, download int64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	v, ok := b.devices[name]
+	if !ok {
+		return fmt.Errorf("device %s does not exist", name)
+	}
+
+	v.upload, v.download = upload, download
+	return nil
+}
+
+func (b *Backend) SetLatestHandshake(name string, t time.Time) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	v, ok := b.devices[name]
+	if !ok {
+		return fmt.Errorf("device %s does not exist", name)
+	}
+
+	v.handshake = t
+	return nil
+}
+
+func (b *Backend) IsUp(name string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	_, ok := b.devices[name]
+	return ok
+}
+
+func (b *Backend) Names() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	items := make([]string, 0, len(b.devices))
+	for name := range b.devices {
+		items = append(items, name)
+	}
+
+	sort.Strings(items)
+	return items
+}
+
+func (b *Backend) lookup(name string) (*device, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	v, ok := b.devices[name]
+	if !ok {
+		return nil, fmt.Errorf("device %s does not exist", name)
+	}
+
+	return v, nil
+}
+
+type Service struct {
+	backend  *Backend
+	name     string
+	endpoint string
+}
+
+func (s *Service) Info() []byte { return nil }
+func (s *Service) IsUp() bool   { return s.backend.IsUp(s.name) }
+
+func (s *Service) PreUp() error {
+	if s.IsUp() {
+		return fmt.Errorf("device %s is already running", s.name)
+	}
+
+	return s.backend.failure(s.name, OpPreUp)
+}
+
+func (s *Service) Up() error {
+	if err := s.backend.failure(s.name, OpUp); err != nil {
+		return err
+	}
+
+	s.backend.mutex.Lock()
+	defer s.backend.mutex.Unlock()
+
+	if _, ok := s.backend.devices[s.name]; ok {
+		return fmt.Errorf("device %s is already running", s.name)
+	}
+
+	s.backend.devices[s.name] = &device{
+		handshake: time.Now(),
+	}
+
+	return nil
+}
+
+func (s *Service) PostUp() error  { return s.backend.failure(s.name, OpPostUp) }
+func (s *Service) PreDown() error { return s.backend.failure(s.name, OpPreDown) }
+
+func (s *Service) Down() error {
+	if err := s.backend.failure(s.name, OpDown); err != nil {
+		return err
+	}
+
+	s.backend.mutex.Lock()
+	defer s.backend.mutex.Unlock()
+
+	if _, ok := s.backend.devices[s.name]; !ok {
+		return fmt.Errorf("device %s does not exist", s.name)
+	}
+
+	delete(s.backend.devices, s.name)
+	return nil
+}
+
+func (s *Service) PostDown() error { return s.backend.failure(s.name, OpPostDown) }
+
+func (s *Service) Transfer() (int64, int64, error) {
+	if err := s.backend.failure(s.name, OpTransfer); err != nil {
+		return 0, 0, err
+	}
+
+	v, err := s.backend.lookup(s.name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	s.backend.mutex.Lock()
+	defer s.backend.mutex.Unlock()
+
+	return v.upload, v.download, nil
+}
+
+func (s *Service) Stats() (*clitypes.Stats, error) {
+	if err := s.backend.failure(s.name, OpStats); err != nil {
+		return nil, err
+	}
+
+	v, err := s.backend.lookup(s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.backend.mutex.Lock()
+	defer s.backend.mutex.Unlock()
+
+	return &clitypes.Stats{
+		Peers: []clitypes.PeerStats{
+			{
+				Endpoint:        s.endpoint,
+				LatestHandshake: v.handshake,
+				RxBytes:         v.upload,
+				TxBytes:         v.download,
+			},
+		},
+	}, nil
+}
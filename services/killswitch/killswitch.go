@@ -0,0 +1,78 @@
+package killswitch
+
+import (
+	"net"
+
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+)
+
+const (
+	Name = "sentinelcli"
+)
+
+var (
+	DefaultAllowed = func() []wireguardtypes.IPNet {
+		var items []wireguardtypes.IPNet
+		for _, s := range []string{
+			"10.0.0.0/8",
+			"172.16.0.0/12",
+			"192.168.0.0/16",
+			"169.254.0.0/16",
+			"224.0.0.0/4",
+			"255.255.255.255/32",
+			"fc00::/7",
+			"fe80::/10",
+			"ff00::/8",
+		} {
+			item, err := wireguardtypes.ParseIPNet(s)
+			if err != nil {
+				panic(err)
+			}
+
+			items = append(items, item)
+		}
+
+		return items
+	}()
+)
+
+type KillSwitch struct {
+	iFaces    []string
+	endpoints []wireguardtypes.Endpoint
+	allowed   []wireguardtypes.IPNet
+}
+
+func NewKillSwitch() *KillSwitch {
+	return &KillSwitch{
+		allowed: DefaultAllowed,
+	}
+}
+
+func (k *KillSwitch) WithInterfaces(v ...string) *KillSwitch { k.iFaces = v; return k }
+
+func (k *KillSwitch) WithEndpoints(v ...wireguardtypes.Endpoint) *KillSwitch {
+	k.endpoints = v
+	return k
+}
+
+func (k *KillSwitch) WithAllowed(v []wireguardtypes.IPNet) *KillSwitch {
+	k.allowed = append(append([]wireguardtypes.IPNet{}, DefaultAllowed...), v...)
+	return k
+}
+
+func (k *KillSwitch) allowedByFamily() (v4, v6 []string) {
+	for _, item := range k.allowed {
+		if item.IsIPv4() {
+			v4 = append(v4, item.String())
+		} else {
+			v6 = append(v6, item.String())
+		}
+	}
+
+	return v4, v6
+}
+
+func isEndpointIPv4(v wireguardtypes.Endpoint) bool {
+	ip := net.ParseIP(v.Host)
+	return ip != nil && ip.To4() != nil
+}
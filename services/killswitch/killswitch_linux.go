@@ -0,0 +1,175 @@
+package killswitch
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func hasNFTables() bool {
+	_, err := exec.LookPath("nft")
+	return err == nil
+}
+
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (k *KillSwitch) nftRuleset() string {
+	var (
+		v4, v6 = k.allowedByFamily()
+		output strings.Builder
+	)
+
+	output.WriteString(fmt.Sprintf("table inet %s\n", Name))
+	output.WriteString(fmt.Sprintf("delete table inet %s\n", Name))
+	output.WriteString(fmt.Sprintf("table inet %s {\n", Name))
+	output.WriteString("\tchain output {\n")
+	output.WriteString("\t\ttype filter hook output priority 0; policy drop;\n")
+	output.WriteString("\t\toifname \"lo\" accept\n")
+	for _, iFace := range k.iFaces {
+		output.WriteString(fmt.Sprintf("\t\toifname %q accept\n", iFace))
+	}
+
+	for _, endpoint := range k.endpoints {
+		if endpoint.IsEmpty() {
+			continue
+		}
+
+		family := "ip6"
+		if isEndpointIPv4(endpoint) {
+			family = "ip"
+		}
+
+		output.WriteString(
+			fmt.Sprintf("\t\t%s daddr %s udp dport %d accept\n", family, endpoint.Host, endpoint.Port),
+		)
+	}
+
+	if len(v4) > 0 {
+		output.WriteString(fmt.Sprintf("\t\tip daddr { %s } accept\n", strings.Join(v4, ", ")))
+	}
+	if len(v6) > 0 {
+		output.WriteString(fmt.Sprintf("\t\tip6 daddr { %s } accept\n", strings.Join(v6, ", ")))
+	}
+
+	output.WriteString("\t}\n")
+	output.WriteString("}\n")
+
+	return output.String()
+}
+
+func (k *KillSwitch) enableNFTables() error {
+	cmd := exec.Command("nft", "-f", "-")
+	cmd.Stdin = bytes.NewBufferString(k.nftRuleset())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (k *KillSwitch) enableIPTables(name string, ipv4 bool) error {
+	chain := strings.ToUpper(Name)
+	if err := exec.Command(name, "-N", chain).Run(); err != nil {
+		if err := run(name, "-F", chain); err != nil {
+			return err
+		}
+	}
+
+	rules := [][]string{
+		{"-o", "lo", "-j", "ACCEPT"},
+	}
+
+	for _, iFace := range k.iFaces {
+		rules = append(rules, []string{"-o", iFace, "-j", "ACCEPT"})
+	}
+
+	for _, endpoint := range k.endpoints {
+		if endpoint.IsEmpty() || isEndpointIPv4(endpoint) != ipv4 {
+			continue
+		}
+
+		rules = append(rules,
+			[]string{"-d", endpoint.Host, "-p", "udp", "--dport", fmt.Sprintf("%d", endpoint.Port), "-j", "ACCEPT"},
+		)
+	}
+
+	v4, v6 := k.allowedByFamily()
+	allowed := v6
+	if ipv4 {
+		allowed = v4
+	}
+
+	for _, s := range allowed {
+		rules = append(rules, []string{"-d", s, "-j", "ACCEPT"})
+	}
+
+	rules = append(rules, []string{"-j", "DROP"})
+	for _, rule := range rules {
+		if err := run(name, append([]string{"-A", chain}, rule...)...); err != nil {
+			return err
+		}
+	}
+
+	if err := exec.Command(name, "-C", "OUTPUT", "-j", chain).Run(); err == nil {
+		return nil
+	}
+
+	return run(name, "-I", "OUTPUT", "-j", chain)
+}
+
+func (k *KillSwitch) disableIPTables(name string) error {
+	chain := strings.ToUpper(Name)
+	if err := exec.Command(name, "-C", "OUTPUT", "-j", chain).Run(); err != nil {
+		return nil
+	}
+
+	if err := run(name, "-D", "OUTPUT", "-j", chain); err != nil {
+		return err
+	}
+	if err := run(name, "-F", chain); err != nil {
+		return err
+	}
+
+	return run(name, "-X", chain)
+}
+
+func (k *KillSwitch) Enable() error {
+	if hasNFTables() {
+		return k.enableNFTables()
+	}
+
+	if err := k.enableIPTables("iptables", true); err != nil {
+		return err
+	}
+
+	return k.enableIPTables("ip6tables", false)
+}
+
+func (k *KillSwitch) Disable() error {
+	if hasNFTables() {
+		if !k.IsEnabled() {
+			return nil
+		}
+
+		return run("nft", "delete", "table", "inet", Name)
+	}
+
+	if err := k.disableIPTables("iptables"); err != nil {
+		return err
+	}
+
+	return k.disableIPTables("ip6tables")
+}
+
+func (k *KillSwitch) IsEnabled() bool {
+	if hasNFTables() {
+		return exec.Command("nft", "list", "table", "inet", Name).Run() == nil
+	}
+
+	return exec.Command("iptables", "-C", "OUTPUT", "-j", strings.ToUpper(Name)).Run() == nil
+}
@@ -0,0 +1,12 @@
+package killswitch
+
+import (
+	"github.com/pkg/errors"
+)
+
+func (k *KillSwitch) Enable() error {
+	return errors.New("kill switch is not supported on this platform")
+}
+
+func (k *KillSwitch) Disable() error  { return nil }
+func (k *KillSwitch) IsEnabled() bool { return false }
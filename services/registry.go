@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sentinel-official/cli-client/services/v2ray"
+	v2raytypes "github.com/sentinel-official/cli-client/services/v2ray/types"
+	"github.com/sentinel-official/cli-client/services/wireguard"
+	wireguardtypes "github.com/sentinel-official/cli-client/services/wireguard/types"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+type Factory func(home string, status *clitypes.ServiceStatus, cfg interface{}) (clitypes.Service, error)
+
+type Registry struct {
+	mutex     sync.RWMutex
+	factories map[uint64]map[string]Factory
+	defaults  map[uint64]string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[uint64]map[string]Factory),
+		defaults:  make(map[uint64]string),
+	}
+}
+
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry().
+		Register(clitypes.ServiceTypeWireGuard, clitypes.ServiceBackendWgQuick, newWgQuick).
+		Register(clitypes.ServiceTypeWireGuard, clitypes.ServiceBackendUserspace, newUserspace).
+		Register(clitypes.ServiceTypeV2Ray, clitypes.ServiceBackendV2Ray, newV2Ray)
+
+	registerPlatformBackends(r)
+	return r
+}
+
+func (r *Registry) Register(t uint64, name string, factory Factory) *Registry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.factories[t]; !ok {
+		r.factories[t] = make(map[string]Factory)
+	}
+	if _, ok := r.defaults[t]; !ok {
+		r.defaults[t] = name
+	}
+
+	r.factories[t][name] = factory
+	return r
+}
+
+func (r *Registry) SetDefault(t uint64, name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.factories[t][name]; !ok {
+		return fmt.Errorf("backend %s is not registered for service type %d", name, t)
+	}
+
+	r.defaults[t] = name
+	return nil
+}
+
+func (r *Registry) Default(t uint64) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.defaults[t]
+}
+
+func (r *Registry) Has(t uint64, name string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	_, ok := r.factories[t][name]
+	return ok
+}
+
+func (r *Registry) Backends(t uint64) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	items := make([]string, 0, len(r.factories[t]))
+	for name := range r.factories[t] {
+		items = append(items, name)
+	}
+
+	sort.Strings(items)
+	return items
+}
+
+func (r *Registry) New(home string, status *clitypes.ServiceStatus, cfg interface{}) (clitypes.Service, error) {
+	name := status.Backend
+	if name == "" {
+		switch status.Mode {
+		case clitypes.ServiceModeProxy:
+			name = clitypes.ServiceBackendUserspace
+		case clitypes.ServiceModeNamespace:
+			name = clitypes.ServiceBackendNamespace
+		default:
+			name = r.Default(status.Type)
+		}
+	}
+
+	r.mutex.RLock()
+	factory, ok := r.factories[status.Type][name]
+	r.mutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("backend %s is not registered for service type %d", name, status.Type)
+	}
+
+	return factory(home, status, cfg)
+}
+
+func wireGuardConfig(status *clitypes.ServiceStatus, cfg interface{}) (*wireguardtypes.Config, error) {
+	if cfg == nil {
+		return &wireguardtypes.Config{Name: status.IFace}, nil
+	}
+
+	v, ok := cfg.(*wireguardtypes.Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid wireguard config type %T", cfg)
+	}
+
+	return v, nil
+}
+
+func newWgQuick(home string, status *clitypes.ServiceStatus, cfg interface{}) (clitypes.Service, error) {
+	v, err := wireGuardConfig(status, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return wireguard.NewWireGuard().WithConfig(v).WithHome(home), nil
+}
+
+func newUserspace(home string, status *clitypes.ServiceStatus, cfg interface{}) (clitypes.Service, error) {
+	v, err := wireGuardConfig(status, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return wireguard.NewUserspaceService(v, home, status.Proxy), nil
+}
+
+func newV2Ray(home string, status *clitypes.ServiceStatus, cfg interface{}) (clitypes.Service, error) {
+	if cfg == nil {
+		cfg = &v2raytypes.Config{Name: status.IFace}
+	}
+
+	v, ok := cfg.(*v2raytypes.Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid v2ray config type %T", cfg)
+	}
+
+	return v2ray.NewService(v, home), nil
+}
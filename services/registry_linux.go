@@ -0,0 +1,32 @@
+package services
+
+import (
+	"github.com/sentinel-official/cli-client/services/wireguard"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func newNetlink(home string, status *clitypes.ServiceStatus, cfg interface{}) (clitypes.Service, error) {
+	v, err := wireGuardConfig(status, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return wireguard.NewNetlink().WithConfig(v).WithHome(home), nil
+}
+
+func newNamespace(home string, status *clitypes.ServiceStatus, cfg interface{}) (clitypes.Service, error) {
+	v, err := wireGuardConfig(status, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return wireguard.NewNamespace().WithConfig(v).WithHome(home).WithNamespace(status.Namespace), nil
+}
+
+func registerPlatformBackends(r *Registry) {
+	r.Register(clitypes.ServiceTypeWireGuard, clitypes.ServiceBackendNamespace, newNamespace)
+	r.Register(clitypes.ServiceTypeWireGuard, clitypes.ServiceBackendNetlink, newNetlink)
+	if wireguard.IsNetlinkSupported() {
+		_ = r.SetDefault(clitypes.ServiceTypeWireGuard, clitypes.ServiceBackendNetlink)
+	}
+}
@@ -0,0 +1,55 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/sentinel-official/cli-client/services"
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+func TestRegistryNewLegacyBackend(t *testing.T) {
+	var (
+		registry = services.NewRegistry()
+		created  string
+	)
+
+	for _, name := range []string{clitypes.ServiceBackendWgQuick, clitypes.ServiceBackendUserspace, clitypes.ServiceBackendNamespace} {
+		name := name
+		registry.Register(clitypes.ServiceTypeWireGuard, name, func(_ string, _ *clitypes.ServiceStatus, _ interface{}) (clitypes.Service, error) {
+			created = name
+			return nil, nil
+		})
+	}
+
+	if err := registry.SetDefault(clitypes.ServiceTypeWireGuard, clitypes.ServiceBackendWgQuick); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode    string
+		backend string
+		want    string
+	}{
+		{"", "", clitypes.ServiceBackendWgQuick},
+		{clitypes.ServiceModeTunnel, "", clitypes.ServiceBackendWgQuick},
+		{clitypes.ServiceModeProxy, "", clitypes.ServiceBackendUserspace},
+		{clitypes.ServiceModeNamespace, "", clitypes.ServiceBackendNamespace},
+		{clitypes.ServiceModeProxy, clitypes.ServiceBackendWgQuick, clitypes.ServiceBackendWgQuick},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+tt.backend, func(t *testing.T) {
+			status := clitypes.NewServiceStatus().
+				WithType(clitypes.ServiceTypeWireGuard).
+				WithMode(tt.mode).
+				WithBackend(tt.backend)
+
+			if _, err := registry.New("", status, nil); err != nil {
+				t.Fatal(err)
+			}
+			if created != tt.want {
+				t.Fatalf("expected backend %s, got %s", tt.want, created)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+package services
+
+func registerPlatformBackends(_ *Registry) {}
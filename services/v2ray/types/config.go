@@ -0,0 +1,183 @@
+package types
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	transports = map[byte]string{
+		TransportDomainSocket: "domainsocket",
+		TransportGUN:          "grpc",
+		TransportHTTP:         "http",
+		TransportMKCP:         "kcp",
+		TransportQUIC:         "quic",
+		TransportTCP:          "tcp",
+		TransportWebSocket:    "ws",
+	}
+)
+
+func TransportFromByte(v byte) (string, error) {
+	s, ok := transports[v]
+	if !ok {
+		return "", fmt.Errorf("invalid transport %d", v)
+	}
+
+	return s, nil
+}
+
+type Config struct {
+	Name      string
+	APIListen uint16
+	Listen    uint16
+	Host      string
+	Port      uint16
+	Transport string
+	UUID      UUID
+}
+
+func NewConfigFromInfo(name, host string, info []byte, uid *UUID, apiListen, listen uint16) (*Config, error) {
+	transport, err := TransportFromByte(info[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		Name:      name,
+		APIListen: apiListen,
+		Listen:    listen,
+		Host:      host,
+		Port:      binary.BigEndian.Uint16(info[0:2]),
+		Transport: transport,
+		UUID:      *uid,
+	}, nil
+}
+
+func (c *Config) ListenAddress() string {
+	return fmt.Sprintf("127.0.0.1:%d", c.Listen)
+}
+
+func (c *Config) APIListenAddress() string {
+	return fmt.Sprintf("127.0.0.1:%d", c.APIListen)
+}
+
+func (c *Config) ToJSON() ([]byte, error) {
+	v := map[string]interface{}{
+		"log": map[string]interface{}{
+			"loglevel": "warning",
+		},
+		"api": map[string]interface{}{
+			"services": []string{"StatsService"},
+			"tag":      "api",
+		},
+		"inbounds": []interface{}{
+			map[string]interface{}{
+				"listen":   "127.0.0.1",
+				"port":     c.APIListen,
+				"protocol": "dokodemo-door",
+				"settings": map[string]interface{}{
+					"address": "127.0.0.1",
+				},
+				"tag": "api",
+			},
+			map[string]interface{}{
+				"listen":   "127.0.0.1",
+				"port":     c.Listen,
+				"protocol": "socks",
+				"settings": map[string]interface{}{
+					"auth": "noauth",
+					"ip":   "127.0.0.1",
+					"udp":  true,
+				},
+				"sniffing": map[string]interface{}{
+					"enabled":      true,
+					"destOverride": []string{"http", "tls"},
+				},
+				"tag": "proxy",
+			},
+		},
+		"outbounds": []interface{}{
+			map[string]interface{}{
+				"protocol": "vmess",
+				"settings": map[string]interface{}{
+					"vnext": []interface{}{
+						map[string]interface{}{
+							"address": c.Host,
+							"port":    c.Port,
+							"users": []interface{}{
+								map[string]interface{}{
+									"id":      c.UUID.String(),
+									"alterId": 0,
+								},
+							},
+						},
+					},
+				},
+				"streamSettings": map[string]interface{}{
+					"network": c.Transport,
+				},
+				"tag": "vmess",
+			},
+		},
+		"policy": map[string]interface{}{
+			"system": map[string]interface{}{
+				"statsOutboundDownlink": true,
+				"statsOutboundUplink":   true,
+			},
+		},
+		"routing": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"inboundTag":  []string{"api"},
+					"outboundTag": "api",
+					"type":        "field",
+				},
+			},
+		},
+		"stats": map[string]interface{}{},
+	}
+
+	return json.MarshalIndent(v, "", "  ")
+}
+
+func (c *Config) FilePath(dir string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.json", c.Name))
+}
+
+func (c *Config) WriteToFile(dir string) error {
+	data, err := c.ToJSON()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.FilePath(dir), data, 0600)
+}
+
+func ReadAPIListen(path string) (uint16, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var v struct {
+		Inbounds []struct {
+			Port uint16 `json:"port"`
+			Tag  string `json:"tag"`
+		} `json:"inbounds"`
+	}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0, err
+	}
+
+	for _, item := range v.Inbounds {
+		if item.Tag == "api" {
+			return item.Port, nil
+		}
+	}
+
+	return 0, fmt.Errorf("api inbound does not exist in %s", path)
+}
@@ -0,0 +1,16 @@
+package types
+
+const (
+	InstancePrefix = "v2ray"
+	ProxyVMess     = 0x01
+)
+
+const (
+	TransportDomainSocket = 0x01 + iota
+	TransportGUN
+	TransportHTTP
+	TransportMKCP
+	TransportQUIC
+	TransportTCP
+	TransportWebSocket
+)
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+type UUID [16]byte
+
+func NewUUID() (*UUID, error) {
+	var v UUID
+	if _, err := rand.Read(v[:]); err != nil {
+		return nil, err
+	}
+
+	v[6] = (v[6] & 0x0f) | 0x40
+	v[8] = (v[8] & 0x3f) | 0x80
+
+	return &v, nil
+}
+
+func NewUUIDFromBytes(v []byte) (*UUID, error) {
+	if len(v) != 16 {
+		return nil, fmt.Errorf("invalid uuid length %d", len(v))
+	}
+
+	var u UUID
+	copy(u[:], v)
+
+	return &u, nil
+}
+
+func (u *UUID) Bytes() []byte {
+	return u[:]
+}
+
+func (u *UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", s[0:8], s[8:12], s[12:16], s[16:20], s[20:32])
+}
@@ -0,0 +1,196 @@
+package v2ray
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sentinel-official/cli-client/services/v2ray/types"
+	clienttypes "github.com/sentinel-official/cli-client/types"
+)
+
+const (
+	StartTimeout = 10 * time.Second
+)
+
+var (
+	_ clienttypes.Service = (*V2Ray)(nil)
+)
+
+type V2Ray struct {
+	cfg  *types.Config
+	info []byte
+	home string
+}
+
+func NewV2Ray() *V2Ray {
+	return &V2Ray{}
+}
+
+func NewService(cfg *types.Config, home string) clienttypes.Service {
+	return NewV2Ray().WithConfig(cfg).WithHome(home)
+}
+
+func (v *V2Ray) WithConfig(c *types.Config) *V2Ray { v.cfg = c; return v }
+func (v *V2Ray) WithInfo(b []byte) *V2Ray          { v.info = b; return v }
+func (v *V2Ray) WithHome(s string) *V2Ray          { v.home = s; return v }
+
+func (v *V2Ray) Info() []byte { return v.info }
+
+func (v *V2Ray) pidFilePath() string {
+	return filepath.Join(v.home, fmt.Sprintf("%s.pid", v.cfg.Name))
+}
+
+func (v *V2Ray) apiListenAddress() (string, error) {
+	port, err := types.ReadAPIListen(v.cfg.FilePath(v.home))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("127.0.0.1:%d", port), nil
+}
+
+func (v *V2Ray) pid() (int, error) {
+	data, err := os.ReadFile(v.pidFilePath())
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+func (v *V2Ray) IsUp() bool {
+	if _, err := v.pid(); err != nil {
+		return false
+	}
+
+	address, err := v.apiListenAddress()
+	if err != nil {
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		return false
+	}
+
+	_ = conn.Close()
+	return true
+}
+
+func (v *V2Ray) PreUp() error {
+	return v.cfg.WriteToFile(v.home)
+}
+
+func (v *V2Ray) Up() error {
+	cmd := exec.Command("v2ray", "run", "-c", v.cfg.FilePath(v.home))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	go func() { _ = cmd.Wait() }()
+
+	return os.WriteFile(v.pidFilePath(), []byte(strconv.Itoa(cmd.Process.Pid)), 0600)
+}
+
+func (v *V2Ray) PostUp() error {
+	for start := time.Now(); time.Since(start) < StartTimeout; time.Sleep(250 * time.Millisecond) {
+		if v.IsUp() {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("v2ray did not start within %s", StartTimeout)
+}
+
+func (v *V2Ray) PreDown() error { return nil }
+
+func (v *V2Ray) Down() error {
+	pid, err := v.pid()
+	if err != nil {
+		return err
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := process.Kill(); err != nil {
+		return err
+	}
+
+	return os.Remove(v.pidFilePath())
+}
+
+func (v *V2Ray) PostDown() error {
+	cfgFilePath := v.cfg.FilePath(v.home)
+	if _, err := os.Stat(cfgFilePath); err != nil {
+		return nil
+	}
+
+	return os.Remove(cfgFilePath)
+}
+
+func (v *V2Ray) Transfer() (u int64, d int64, err error) {
+	address, err := v.apiListenAddress()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	output, err := exec.Command("v2ray", "api", "stats", "-s", address, "-json").Output()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var res struct {
+		Stat []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"stat"`
+	}
+
+	if err := json.Unmarshal(output, &res); err != nil {
+		return 0, 0, err
+	}
+
+	for _, item := range res.Stat {
+		value, err := strconv.ParseInt(strings.Trim(string(item.Value), `"`), 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		switch item.Name {
+		case "outbound>>>vmess>>>traffic>>>downlink":
+			u = value
+		case "outbound>>>vmess>>>traffic>>>uplink":
+			d = value
+		}
+	}
+
+	return u, d, nil
+}
+
+func (v *V2Ray) Stats() (*clienttypes.Stats, error) {
+	u, d, err := v.Transfer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &clienttypes.Stats{
+		Peers: []clienttypes.PeerStats{
+			{
+				RxBytes: u,
+				TxBytes: d,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,223 @@
+package wireguard
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sentinel-official/cli-client/services/wireguard/types"
+	clienttypes "github.com/sentinel-official/cli-client/types"
+)
+
+const (
+	NamespaceConfigDir = "/etc/netns"
+	NamespaceRunDir    = "/var/run/netns"
+)
+
+var (
+	_ clienttypes.Service = (*Namespace)(nil)
+)
+
+type Namespace struct {
+	cfg       *types.Config
+	info      []byte
+	home      string
+	namespace string
+}
+
+func NewNamespace() *Namespace {
+	return &Namespace{}
+}
+
+func (n *Namespace) WithConfig(v *types.Config) *Namespace { n.cfg = v; return n }
+func (n *Namespace) WithInfo(v []byte) *Namespace          { n.info = v; return n }
+func (n *Namespace) WithHome(v string) *Namespace          { n.home = v; return n }
+func (n *Namespace) WithNamespace(v string) *Namespace     { n.namespace = v; return n }
+
+func (n *Namespace) Info() []byte { return n.info }
+
+func (n *Namespace) cfgFilePath() string {
+	return filepath.Join(n.home, fmt.Sprintf("%s.conf", n.cfg.Name))
+}
+
+func (n *Namespace) configDir() string {
+	return filepath.Join(NamespaceConfigDir, n.namespace)
+}
+
+func (n *Namespace) run(stdin []byte, name string, args ...string) ([]byte, error) {
+	var (
+		cmd    = exec.Command(name, args...)
+		stderr bytes.Buffer
+	)
+
+	cmd.Stderr = &stderr
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
+}
+
+func (n *Namespace) IsUp() bool {
+	if n.namespace == "" {
+		return false
+	}
+
+	_, err := n.run(nil, "ip", "-n", n.namespace, "link", "show", n.cfg.Name)
+	return err == nil
+}
+
+func (n *Namespace) PreUp() error {
+	if n.namespace == "" {
+		return fmt.Errorf("namespace of device %s cannot be empty", n.cfg.Name)
+	}
+	if n.IsUp() {
+		return fmt.Errorf("device %s is already running in namespace %s", n.cfg.Name, n.namespace)
+	}
+
+	return n.cfg.WriteToFile(n.home)
+}
+
+func (n *Namespace) exists() bool {
+	_, err := os.Stat(filepath.Join(NamespaceRunDir, n.namespace))
+	return err == nil
+}
+
+func (n *Namespace) Up() error {
+	if n.exists() {
+		if _, err := n.run(nil, "ip", "netns", "del", n.namespace); err != nil {
+			return err
+		}
+
+		_ = os.RemoveAll(n.configDir())
+	}
+
+	if _, err := n.run(nil, "ip", "netns", "add", n.namespace); err != nil {
+		return err
+	}
+
+	if err := n.up(); err != nil {
+		_, _ = n.run(nil, "ip", "link", "del", n.cfg.Name)
+		_, _ = n.run(nil, "ip", "netns", "del", n.namespace)
+		_ = os.RemoveAll(n.configDir())
+
+		return err
+	}
+
+	return nil
+}
+
+func (n *Namespace) up() error {
+	cfg, err := n.run(nil, "wg-quick", "strip", n.cfgFilePath())
+	if err != nil {
+		return err
+	}
+
+	if _, err := n.run(nil, "ip", "link", "add", n.cfg.Name, "type", "wireguard"); err != nil {
+		return err
+	}
+	if _, err := n.run(cfg, "wg", "setconf", n.cfg.Name, "/dev/stdin"); err != nil {
+		return err
+	}
+	if _, err := n.run(nil, "ip", "link", "set", n.cfg.Name, "netns", n.namespace); err != nil {
+		return err
+	}
+
+	for _, address := range n.cfg.Interface.Addresses {
+		if _, err := n.run(nil, "ip", "-n", n.namespace, "address", "add", address.String(), "dev", n.cfg.Name); err != nil {
+			return err
+		}
+	}
+
+	mtu := DefaultMTU
+	if n.cfg.Interface.MTU > 0 {
+		mtu = int(n.cfg.Interface.MTU)
+	}
+
+	if _, err := n.run(nil, "ip", "-n", n.namespace, "link", "set", "lo", "up"); err != nil {
+		return err
+	}
+	if _, err := n.run(nil, "ip", "-n", n.namespace, "link", "set", n.cfg.Name, "mtu", fmt.Sprintf("%d", mtu), "up"); err != nil {
+		return err
+	}
+
+	for _, peer := range n.cfg.Peers {
+		for _, ip := range peer.AllowedIPs {
+			if _, err := n.run(nil, "ip", "-n", n.namespace, "route", "add", ip.String(), "dev", n.cfg.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return n.writeResolvConf()
+}
+
+func (n *Namespace) writeResolvConf() error {
+	if len(n.cfg.Interface.DNS) == 0 {
+		return nil
+	}
+
+	var output strings.Builder
+	for _, ip := range n.cfg.Interface.DNS {
+		output.WriteString(fmt.Sprintf("nameserver %s\n", ip.String()))
+	}
+	if len(n.cfg.Interface.DNSSearch) > 0 {
+		output.WriteString(fmt.Sprintf("search %s\n", strings.Join(n.cfg.Interface.DNSSearch, " ")))
+	}
+
+	if err := os.MkdirAll(n.configDir(), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(n.configDir(), "resolv.conf"), []byte(output.String()), 0644)
+}
+
+func (n *Namespace) PostUp() error  { return nil }
+func (n *Namespace) PreDown() error { return nil }
+
+func (n *Namespace) Down() error {
+	if _, err := n.run(nil, "ip", "netns", "del", n.namespace); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(n.configDir())
+}
+
+func (n *Namespace) PostDown() error {
+	if _, err := os.Stat(n.cfgFilePath()); err != nil {
+		return nil
+	}
+
+	return os.Remove(n.cfgFilePath())
+}
+
+func (n *Namespace) Stats() (*clienttypes.Stats, error) {
+	output, err := n.run(nil, "ip", "netns", "exec", n.namespace, "wg", "show", n.cfg.Name, "dump")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDump(output)
+}
+
+func (n *Namespace) Transfer() (u int64, d int64, err error) {
+	stats, err := n.Stats()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, peer := range stats.Peers {
+		u += peer.RxBytes
+		d += peer.TxBytes
+	}
+
+	return u, d, nil
+}
@@ -0,0 +1,565 @@
+package wireguard
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	"golang.zx2c4.com/wireguard/wgctrl"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/sentinel-official/cli-client/services/wireguard/types"
+	clienttypes "github.com/sentinel-official/cli-client/types"
+)
+
+const (
+	DefaultTable             = 51820
+	ResolvConfBackupFilename = "resolv.conf.backup"
+	RuleProtocol             = 0x53
+)
+
+var (
+	_ clienttypes.Service = (*Netlink)(nil)
+
+	dnsMutex sync.Mutex
+)
+
+func IsNetlinkSupported() bool {
+	conn, err := genetlink.Dial(nil)
+	if err != nil {
+		return false
+	}
+
+	defer conn.Close()
+
+	_, err = conn.GetFamily("wireguard")
+	return err == nil
+}
+
+type Netlink struct {
+	cfg  *types.Config
+	info []byte
+	home string
+}
+
+func NewNetlink() *Netlink {
+	return &Netlink{}
+}
+
+func (n *Netlink) WithConfig(v *types.Config) *Netlink { n.cfg = v; return n }
+func (n *Netlink) WithInfo(v []byte) *Netlink          { n.info = v; return n }
+func (n *Netlink) WithHome(v string) *Netlink          { n.home = v; return n }
+
+func (n *Netlink) Info() []byte { return n.info }
+
+func (n *Netlink) RealInterface() (string, error) {
+	return n.cfg.Name, nil
+}
+
+func (n *Netlink) IsUp() bool {
+	link, err := netlink.LinkByName(n.cfg.Name)
+	if err != nil {
+		return false
+	}
+
+	return link.Type() == "wireguard"
+}
+
+func (n *Netlink) PreUp() error  { return nil }
+func (n *Netlink) PostUp() error { return nil }
+
+func (n *Netlink) Up() error {
+	link := &netlink.Wireguard{
+		LinkAttrs: netlink.LinkAttrs{
+			Name: n.cfg.Name,
+			MTU:  DefaultMTU,
+		},
+	}
+
+	if n.cfg.Interface.MTU > 0 {
+		link.MTU = int(n.cfg.Interface.MTU)
+	}
+
+	if err := netlink.LinkAdd(link); err != nil {
+		return err
+	}
+
+	if err := n.up(link); err != nil {
+		_ = netlink.LinkDel(link)
+		_ = n.deleteRules()
+		_ = n.revertDNS()
+
+		return err
+	}
+
+	return nil
+}
+
+func (n *Netlink) up(link netlink.Link) error {
+	cfg, err := n.deviceConfig()
+	if err != nil {
+		return err
+	}
+
+	client, err := wgctrl.New()
+	if err != nil {
+		return err
+	}
+
+	defer client.Close()
+
+	if err := client.ConfigureDevice(n.cfg.Name, cfg); err != nil {
+		return err
+	}
+
+	for _, address := range n.cfg.Interface.Addresses {
+		addr, err := netlink.ParseAddr(address.String())
+		if err != nil {
+			return err
+		}
+		if err := netlink.AddrAdd(link, addr); err != nil {
+			return err
+		}
+	}
+
+	if err := netlink.LinkSetUp(link); err != nil {
+		return err
+	}
+	if err := n.setDNS(); err != nil {
+		return err
+	}
+
+	return n.addRoutes(link)
+}
+
+func (n *Netlink) PreDown() error { return nil }
+
+func (n *Netlink) Down() error {
+	link, err := netlink.LinkByName(n.cfg.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := netlink.LinkDel(link); err != nil {
+		return err
+	}
+	if err := n.deleteRules(); err != nil {
+		return err
+	}
+
+	return n.revertDNS()
+}
+
+func (n *Netlink) PostDown() error {
+	if err := n.deleteRules(); err != nil {
+		return err
+	}
+
+	return n.revertDNS()
+}
+
+func (n *Netlink) Transfer() (u int64, d int64, err error) {
+	client, err := wgctrl.New()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	defer client.Close()
+
+	device, err := client.Device(n.cfg.Name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, peer := range device.Peers {
+		u += peer.ReceiveBytes
+		d += peer.TransmitBytes
+	}
+
+	return u, d, nil
+}
+
+func (n *Netlink) Stats() (*clienttypes.Stats, error) {
+	client, err := wgctrl.New()
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.Close()
+
+	device, err := client.Device(n.cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &clienttypes.Stats{}
+	for _, peer := range device.Peers {
+		item := clienttypes.PeerStats{
+			PublicKey:           peer.PublicKey.String(),
+			LatestHandshake:     peer.LastHandshakeTime,
+			RxBytes:             peer.ReceiveBytes,
+			TxBytes:             peer.TransmitBytes,
+			PersistentKeepalive: uint16(peer.PersistentKeepaliveInterval / time.Second),
+		}
+
+		if peer.Endpoint != nil {
+			item.Endpoint = peer.Endpoint.String()
+		}
+		if item.LatestHandshake.Unix() == 0 {
+			item.LatestHandshake = time.Time{}
+		}
+
+		stats.Peers = append(stats.Peers, item)
+	}
+
+	return stats, nil
+}
+
+func (n *Netlink) hasDefaultRoute() bool {
+	for _, peer := range n.cfg.Peers {
+		for _, ip := range peer.AllowedIPs {
+			if ip.Net == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (n *Netlink) deviceConfig() (cfg wgtypes.Config, err error) {
+	var (
+		privateKey = wgtypes.Key(n.cfg.Interface.PrivateKey)
+		listenPort = int(n.cfg.Interface.ListenPort)
+	)
+
+	cfg = wgtypes.Config{
+		PrivateKey:   &privateKey,
+		ListenPort:   &listenPort,
+		ReplacePeers: true,
+		Peers:        make([]wgtypes.PeerConfig, 0, len(n.cfg.Peers)),
+	}
+
+	if n.hasDefaultRoute() {
+		table := DefaultTable
+		cfg.FirewallMark = &table
+	}
+
+	for _, peer := range n.cfg.Peers {
+		item := wgtypes.PeerConfig{
+			PublicKey:         wgtypes.Key(peer.PublicKey),
+			ReplaceAllowedIPs: true,
+			AllowedIPs:        make([]net.IPNet, 0, len(peer.AllowedIPs)),
+		}
+
+		if !peer.PresharedKey.IsZero() {
+			presharedKey := wgtypes.Key(peer.PresharedKey)
+			item.PresharedKey = &presharedKey
+		}
+
+		if !peer.Endpoint.IsEmpty() {
+			item.Endpoint, err = net.ResolveUDPAddr("udp", peer.Endpoint.String())
+			if err != nil {
+				return cfg, err
+			}
+		}
+
+		if peer.PersistentKeepalive > 0 {
+			keepalive := time.Duration(peer.PersistentKeepalive) * time.Second
+			item.PersistentKeepaliveInterval = &keepalive
+		}
+
+		for _, ip := range peer.AllowedIPs {
+			item.AllowedIPs = append(item.AllowedIPs, ip.Raw())
+		}
+
+		cfg.Peers = append(cfg.Peers, item)
+	}
+
+	return cfg, nil
+}
+
+func (n *Netlink) addRoutes(link netlink.Link) error {
+	var items []types.IPNet
+	for _, peer := range n.cfg.Peers {
+		items = append(items, peer.AllowedIPs...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Net > items[j].Net
+	})
+
+	for _, item := range items {
+		var (
+			dst   = item.Raw()
+			route = &netlink.Route{
+				LinkIndex: link.Attrs().Index,
+				Dst:       &dst,
+				Scope:     netlink.SCOPE_LINK,
+			}
+		)
+
+		if item.Net == 0 {
+			if err := n.addDefaultRoute(route, item.IsIPv4()); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := netlink.RouteReplace(route); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *Netlink) addDefaultRoute(route *netlink.Route, ipv4 bool) error {
+	family := netlink.FAMILY_V6
+	if ipv4 {
+		family = netlink.FAMILY_V4
+	}
+
+	route.Table = DefaultTable
+	if err := netlink.RouteReplace(route); err != nil {
+		return err
+	}
+
+	rule := netlink.NewRule()
+	rule.Family = family
+	rule.Mark = DefaultTable
+	rule.Invert = true
+	rule.Table = DefaultTable
+	rule.Protocol = RuleProtocol
+	if err := netlink.RuleAdd(rule); err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	rule = netlink.NewRule()
+	rule.Family = family
+	rule.Table = unix.RT_TABLE_MAIN
+	rule.SuppressPrefixlen = 0
+	rule.Protocol = RuleProtocol
+	if err := netlink.RuleAdd(rule); err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	if ipv4 {
+		return os.WriteFile("/proc/sys/net/ipv4/conf/all/src_valid_mark", []byte("1"), 0644)
+	}
+
+	return nil
+}
+
+func (n *Netlink) isTableShared() (bool, error) {
+	client, err := wgctrl.New()
+	if err != nil {
+		return false, err
+	}
+
+	defer client.Close()
+
+	devices, err := client.Devices()
+	if err != nil {
+		return false, err
+	}
+
+	for _, device := range devices {
+		if device.Name != n.cfg.Name && device.FirewallMark == DefaultTable {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (n *Netlink) deleteRules() error {
+	shared, err := n.isTableShared()
+	if err != nil {
+		return err
+	}
+	if shared {
+		return nil
+	}
+
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		rules, err := netlink.RuleList(family)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < len(rules); i++ {
+			if rules[i].Protocol != RuleProtocol {
+				continue
+			}
+
+			var (
+				isMark     = rules[i].Table == DefaultTable && rules[i].Mark == DefaultTable
+				isSuppress = rules[i].Table == unix.RT_TABLE_MAIN && rules[i].SuppressPrefixlen == 0
+			)
+
+			if !isMark && !isSuppress {
+				continue
+			}
+			if err := netlink.RuleDel(&rules[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (n *Netlink) dnsFilePath() string {
+	return filepath.Join(n.home, fmt.Sprintf("%s.dns", n.cfg.Name))
+}
+
+func (n *Netlink) setDNS() error {
+	if len(n.cfg.Interface.DNS) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, ip := range n.cfg.Interface.DNS {
+		buf.WriteString(fmt.Sprintf("nameserver %s\n", ip.String()))
+	}
+	if len(n.cfg.Interface.DNSSearch) > 0 {
+		buf.WriteString("search")
+		for _, s := range n.cfg.Interface.DNSSearch {
+			buf.WriteString(" " + s)
+		}
+		buf.WriteString("\n")
+	}
+
+	if _, err := exec.LookPath("resolvconf"); err == nil {
+		cmd := exec.Command("resolvconf", "-a", "tun."+n.cfg.Name, "-m", "0", "-x")
+		cmd.Stdin = &buf
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+
+	dnsMutex.Lock()
+	defer dnsMutex.Unlock()
+
+	backupFilePath := filepath.Join(n.home, ResolvConfBackupFilename)
+	if _, err := os.Stat(backupFilePath); os.IsNotExist(err) {
+		data, err := os.ReadFile("/etc/resolv.conf")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(backupFilePath, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(n.dnsFilePath(), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return n.writeResolvConf()
+}
+
+func (n *Netlink) writeResolvConf() error {
+	filePaths, err := filepath.Glob(filepath.Join(n.home, "*.dns"))
+	if err != nil {
+		return err
+	}
+
+	backupFilePath := filepath.Join(n.home, ResolvConfBackupFilename)
+	if len(filePaths) == 0 {
+		data, err := os.ReadFile(backupFilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+		if err := os.WriteFile("/etc/resolv.conf", data, 0644); err != nil {
+			return err
+		}
+
+		return os.Remove(backupFilePath)
+	}
+
+	sort.Strings(filePaths)
+
+	var (
+		nameservers []string
+		search      []string
+		seen        = make(map[string]bool)
+	)
+
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+
+			for _, v := range fields[1:] {
+				key := fields[0] + " " + v
+				if seen[key] {
+					continue
+				}
+
+				seen[key] = true
+				switch fields[0] {
+				case "nameserver":
+					nameservers = append(nameservers, v)
+				case "search":
+					search = append(search, v)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, v := range nameservers {
+		buf.WriteString(fmt.Sprintf("nameserver %s\n", v))
+	}
+	if len(search) > 0 {
+		buf.WriteString(fmt.Sprintf("search %s\n", strings.Join(search, " ")))
+	}
+
+	return os.WriteFile("/etc/resolv.conf", buf.Bytes(), 0644)
+}
+
+func (n *Netlink) revertDNS() error {
+	if _, err := os.Stat(n.dnsFilePath()); err == nil {
+		dnsMutex.Lock()
+		defer dnsMutex.Unlock()
+
+		if err := os.Remove(n.dnsFilePath()); err != nil {
+			return err
+		}
+
+		return n.writeResolvConf()
+	}
+
+	if _, err := exec.LookPath("resolvconf"); err == nil {
+		cmd := exec.Command("resolvconf", "-d", "tun."+n.cfg.Name, "-f")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+
+	return nil
+}
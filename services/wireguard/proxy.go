@@ -0,0 +1,250 @@
+package wireguard
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	socks5Version = 0x05
+
+	socks5MethodNoAuth       = 0x00
+	socks5MethodNoAcceptable = 0xff
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5ReplySucceeded           = 0x00
+	socks5ReplyFailure             = 0x01
+	socks5ReplyHostUnreachable     = 0x04
+	socks5ReplyCmdNotSupported     = 0x07
+	socks5ReplyAddrTypeUnsupported = 0x08
+
+	proxyDialTimeout = 30 * time.Second
+)
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+func serveProxy(listener net.Listener, dial dialFunc) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go handleProxyConn(conn, dial)
+	}
+}
+
+func handleProxyConn(conn net.Conn, dial dialFunc) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	b, err := reader.Peek(1)
+	if err != nil {
+		return
+	}
+
+	if b[0] == socks5Version {
+		handleSOCKS5(conn, reader, dial)
+		return
+	}
+
+	handleHTTP(conn, reader, dial)
+}
+
+func dialTimeout(dial dialFunc, address string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), proxyDialTimeout)
+	defer cancel()
+
+	return dial(ctx, "tcp", address)
+}
+
+func pipe(conn net.Conn, reader io.Reader, remote net.Conn) {
+	defer remote.Close()
+
+	done := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(remote, reader); done <- struct{}{} }()
+	go func() { _, _ = io.Copy(conn, remote); done <- struct{}{} }()
+
+	<-done
+}
+
+func readSOCKS5Address(reader *bufio.Reader) (string, byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return "", socks5ReplyFailure, err
+	}
+	if header[0] != socks5Version {
+		return "", socks5ReplyFailure, fmt.Errorf("unsupported socks version %d", header[0])
+	}
+	if header[1] != socks5CmdConnect {
+		return "", socks5ReplyCmdNotSupported, fmt.Errorf("unsupported socks command %d", header[1])
+	}
+
+	var host string
+	switch header[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		size := net.IPv4len
+		if header[3] == socks5AddrIPv6 {
+			size = net.IPv6len
+		}
+
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(reader, ip); err != nil {
+			return "", socks5ReplyFailure, err
+		}
+
+		host = net.IP(ip).String()
+	case socks5AddrDomain:
+		size, err := reader.ReadByte()
+		if err != nil {
+			return "", socks5ReplyFailure, err
+		}
+
+		domain := make([]byte, size)
+		if _, err := io.ReadFull(reader, domain); err != nil {
+			return "", socks5ReplyFailure, err
+		}
+
+		host = string(domain)
+	default:
+		return "", socks5ReplyAddrTypeUnsupported, fmt.Errorf("unsupported socks address type %d", header[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(reader, port); err != nil {
+		return "", socks5ReplyFailure, err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), socks5ReplySucceeded, nil
+}
+
+func writeSOCKS5Reply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socks5Version, reply, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+func handleSOCKS5(conn net.Conn, reader *bufio.Reader, dial dialFunc) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(reader, methods); err != nil {
+		return
+	}
+
+	method := byte(socks5MethodNoAcceptable)
+	for _, v := range methods {
+		if v == socks5MethodNoAuth {
+			method = socks5MethodNoAuth
+			break
+		}
+	}
+
+	if _, err := conn.Write([]byte{socks5Version, method}); err != nil {
+		return
+	}
+	if method == socks5MethodNoAcceptable {
+		return
+	}
+
+	address, reply, err := readSOCKS5Address(reader)
+	if err != nil {
+		_ = writeSOCKS5Reply(conn, reply)
+		return
+	}
+
+	remote, err := dialTimeout(dial, address)
+	if err != nil {
+		_ = writeSOCKS5Reply(conn, socks5ReplyHostUnreachable)
+		return
+	}
+
+	if err := writeSOCKS5Reply(conn, socks5ReplySucceeded); err != nil {
+		_ = remote.Close()
+		return
+	}
+
+	pipe(conn, reader, remote)
+}
+
+func writeHTTPError(conn net.Conn, status int, err error) {
+	res := &http.Response{
+		StatusCode:    status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(err.Error() + "\n")),
+		Close:         true,
+		ContentLength: -1,
+	}
+
+	_ = res.Write(conn)
+}
+
+func handleHTTP(conn net.Conn, reader *bufio.Reader, dial dialFunc) {
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		writeHTTPError(conn, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.Method == http.MethodConnect {
+		remote, err := dialTimeout(dial, req.Host)
+		if err != nil {
+			writeHTTPError(conn, http.StatusBadGateway, err)
+			return
+		}
+
+		if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+			_ = remote.Close()
+			return
+		}
+
+		pipe(conn, reader, remote)
+		return
+	}
+
+	if req.URL.Host == "" || req.URL.Scheme != "http" {
+		writeHTTPError(conn, http.StatusBadRequest, errors.New("only absolute http URLs and CONNECT are supported"))
+		return
+	}
+
+	transport := &http.Transport{
+		DialContext:       dial,
+		DisableKeepAlives: true,
+	}
+
+	defer transport.CloseIdleConnections()
+
+	req.RequestURI = ""
+	req.Header.Del("Proxy-Connection")
+	req.Header.Del("Proxy-Authorization")
+
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		writeHTTPError(conn, http.StatusBadGateway, err)
+		return
+	}
+
+	defer res.Body.Close()
+
+	res.Close = true
+	_ = res.Write(conn)
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/binary"
 	"fmt"
 	"net"
 	"os"
@@ -35,6 +36,56 @@ type Peer struct {
 	PersistentKeepalive uint16
 }
 
+var (
+	NodeResolver = IPNet{IP: net.IPv4(10, 8, 0, 1).To4(), Net: 32}
+)
+
+func NewConfigFromInfo(name string, info []byte, key *Key, listenPort uint16, nodeResolver bool, resolvers []net.IP, include, exclude []IPNet) *Config {
+	endpoint := net.IP(info[20 : 20+4])
+	if len(exclude) > 0 {
+		exclude = append(exclude, IPNet{IP: endpoint, Net: 32})
+	}
+
+	allowedIPs := NewAllowedIPs(include, exclude)
+	if nodeResolver {
+		if !ContainsIPNet(allowedIPs, NodeResolver) {
+			allowedIPs = append(allowedIPs, NodeResolver)
+		}
+
+		resolvers = append([]net.IP{NodeResolver.IP}, resolvers...)
+	}
+
+	return &Config{
+		Name: name,
+		Interface: Interface{
+			Addresses: []IPNet{
+				{
+					IP:  net.IP(info[0 : 0+4]),
+					Net: 32,
+				},
+				{
+					IP:  net.IP(info[4 : 4+16]),
+					Net: 128,
+				},
+			},
+			ListenPort: listenPort,
+			PrivateKey: *key,
+			DNS:        resolvers,
+		},
+		Peers: []Peer{
+			{
+				PublicKey:  *NewKey(info[26 : 26+32]),
+				AllowedIPs: allowedIPs,
+				Endpoint: Endpoint{
+					Host: endpoint.String(),
+					Port: binary.BigEndian.Uint16(info[24 : 24+2]),
+				},
+				PersistentKeepalive: DefaultPersistentKeepalive,
+			},
+		},
+	}
+}
+
 func (c *Config) ToWgQuick() string {
 	var output strings.Builder
 	output.WriteString("[Interface]\n")
@@ -109,6 +160,14 @@ func (c *Config) ToWgQuick() string {
 	return output.String()
 }
 
+func (c *Config) SaveToPath(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(c.ToWgQuick()), 0600)
+}
+
 func (c *Config) WriteToFile(dir string) error {
 	path := filepath.Join(dir, fmt.Sprintf("%s.conf", c.Name))
 	return os.WriteFile(path, []byte(c.ToWgQuick()), 0600)
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
+	"fmt"
 
 	"golang.org/x/crypto/curve25519"
 )
@@ -63,3 +64,15 @@ func (k *Key) Public() *Key {
 	curve25519.ScalarBaseMult(&p, (*[KeyLength]byte)(k))
 	return (*Key)(&p)
 }
+
+func ParseKey(s string) (*Key, error) {
+	v, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(v) != KeyLength {
+		return nil, fmt.Errorf("key length must be %d bytes", KeyLength)
+	}
+
+	return NewKey(v), nil
+}
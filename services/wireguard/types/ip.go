@@ -3,6 +3,7 @@ package types
 import (
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 )
 
@@ -15,6 +16,26 @@ func (r *IPNet) String() string {
 	return fmt.Sprintf("%s/%d", r.IP.String(), r.Net)
 }
 
+func (r *IPNet) IsIPv4() bool {
+	return r.IP.To4() != nil
+}
+
+func (r *IPNet) Raw() net.IPNet {
+	if ip := r.IP.To4(); ip != nil {
+		mask := net.CIDRMask(int(r.Net), 8*net.IPv4len)
+		return net.IPNet{
+			IP:   ip.Mask(mask),
+			Mask: mask,
+		}
+	}
+
+	mask := net.CIDRMask(int(r.Net), 8*net.IPv6len)
+	return net.IPNet{
+		IP:   r.IP.To16().Mask(mask),
+		Mask: mask,
+	}
+}
+
 type Endpoint struct {
 	Host string
 	Port uint16
@@ -31,3 +52,128 @@ func (e *Endpoint) String() string {
 func (e *Endpoint) IsEmpty() bool {
 	return e.Host == ""
 }
+
+func ParseIPNets(v []string) ([]IPNet, error) {
+	items := make([]IPNet, 0, len(v))
+	for _, s := range v {
+		item, err := ParseIPNet(s)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func ParseEndpoint(s string) (Endpoint, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	v, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	return Endpoint{
+		Host: host,
+		Port: uint16(v),
+	}, nil
+}
+
+func ParseIPNet(s string) (IPNet, error) {
+	ip, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return IPNet{}, err
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if ip.To4() != nil {
+		if bits == 8*net.IPv6len {
+			if ones < 8*(net.IPv6len-net.IPv4len) {
+				return IPNet{}, fmt.Errorf("invalid prefix length %d of IPv4-mapped address %s", ones, s)
+			}
+
+			ones -= 8 * (net.IPv6len - net.IPv4len)
+		}
+
+		ip = ip.To4()
+	}
+
+	return IPNet{
+		IP:  ip,
+		Net: uint8(ones),
+	}, nil
+}
+
+func (r *IPNet) Contains(v IPNet) bool {
+	if r.IsIPv4() != v.IsIPv4() || r.Net > v.Net {
+		return false
+	}
+
+	n := r.Raw()
+	return n.Contains(v.IP)
+}
+
+func (r *IPNet) Overlaps(v IPNet) bool {
+	return r.Contains(v) || v.Contains(*r)
+}
+
+func (r *IPNet) split() (IPNet, IPNet) {
+	var (
+		n  = r.Raw()
+		ip = make(net.IP, len(n.IP))
+	)
+
+	copy(ip, n.IP)
+	ip[r.Net/8] |= 0x80 >> (r.Net % 8)
+
+	return IPNet{IP: n.IP, Net: r.Net + 1}, IPNet{IP: ip, Net: r.Net + 1}
+}
+
+func (r *IPNet) subtract(v IPNet) []IPNet {
+	if !r.Overlaps(v) {
+		return []IPNet{*r}
+	}
+	if v.Contains(*r) {
+		return nil
+	}
+
+	lo, hi := r.split()
+	return append(lo.subtract(v), hi.subtract(v)...)
+}
+
+func NewAllowedIPs(include, exclude []IPNet) []IPNet {
+	if len(include) == 0 {
+		include = []IPNet{
+			{IP: net.IPv4zero.To4()},
+			{IP: net.IPv6zero},
+		}
+	}
+
+	items := append([]IPNet{}, include...)
+
+	for _, e := range exclude {
+		var result []IPNet
+		for i := 0; i < len(items); i++ {
+			result = append(result, items[i].subtract(e)...)
+		}
+
+		items = result
+	}
+
+	return items
+}
+
+func ContainsIPNet(items []IPNet, v IPNet) bool {
+	for i := 0; i < len(items); i++ {
+		if items[i].Contains(v) {
+			return true
+		}
+	}
+
+	return false
+}
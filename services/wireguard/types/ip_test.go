@@ -0,0 +1,58 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/sentinel-official/cli-client/services/wireguard/types"
+)
+
+func TestParseIPNet(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{"10.0.0.0/8", "10.0.0.0/8", false},
+		{"fd00::/8", "fd00::/8", false},
+		{"::/0", "::/0", false},
+		{"::ffff:10.0.0.0/104", "10.0.0.0/8", false},
+		{"::ffff:10.1.2.3/128", "10.1.2.3/32", false},
+		{"::ffff:0.0.0.0/80", "", true},
+		{"10.0.0.0/33", "", true},
+	}
+
+	for _, tt := range tests {
+		v, err := types.ParseIPNet(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Fatalf("%s: expected an error, got %s", tt.in, v.String())
+			}
+
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", tt.in, err)
+		}
+		if got := v.String(); got != tt.want {
+			t.Fatalf("%s: expected %s, got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestNewAllowedIPsMapped(t *testing.T) {
+	exclude, err := types.ParseIPNets([]string{"::ffff:10.0.0.0/104"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := types.NewAllowedIPs(nil, exclude)
+	for _, item := range items {
+		if item.Overlaps(exclude[0]) {
+			t.Fatalf("allowed ip %s overlaps the excluded %s", item.String(), exclude[0].String())
+		}
+	}
+
+	if !types.ContainsIPNet(items, types.IPNet{IP: []byte{11, 0, 0, 0}, Net: 8}) {
+		t.Fatalf("expected 11.0.0.0/8 in %v", items)
+	}
+}
@@ -1,5 +1,22 @@
 package types
 
+import (
+	"regexp"
+)
+
 const (
-	DefaultInterface = "wg99"
+	InterfacePrefix   = "wg"
+	MaxInterfaceIndex = 99
+	DefaultInterface  = "wg99"
+	UserspacePrefix   = "userspace"
+	NamespacePrefix   = "sentinel"
+
+	DefaultPersistentKeepalive = 15
+	MinMTU                     = 576
+	MaxMTU                     = 9000
+)
+
+var (
+	InterfaceNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_=+.-]{1,15}$`)
+	DomainRegexp        = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
 )
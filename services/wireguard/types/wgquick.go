@@ -0,0 +1,345 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	sectionInterface = "interface"
+	sectionPeer      = "peer"
+)
+
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+type wgQuickParser struct {
+	cfg      *Config
+	section  string
+	seen     map[string]bool
+	warnings []string
+}
+
+func ParseWgQuick(data []byte) (*Config, error) {
+	cfg, _, err := ParseWgQuickWithWarnings(data)
+	return cfg, err
+}
+
+func ParseWgQuickWithWarnings(data []byte) (*Config, []string, error) {
+	var (
+		p = &wgQuickParser{
+			cfg: &Config{},
+		}
+		scanner      = bufio.NewScanner(bytes.NewReader(data))
+		line         = 0
+		hasInterface = false
+	)
+
+	for scanner.Scan() {
+		line++
+
+		s := scanner.Text()
+		if i := strings.IndexByte(s, '#'); i >= 0 {
+			s = s[:i]
+		}
+
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		if strings.HasPrefix(s, "[") {
+			if err := p.parseSection(s); err != nil {
+				return nil, nil, &ParseError{Line: line, Err: err}
+			}
+			if p.section == sectionInterface {
+				if hasInterface {
+					return nil, nil, &ParseError{Line: line, Err: errors.New("duplicate interface section")}
+				}
+
+				hasInterface = true
+			}
+
+			continue
+		}
+
+		warning, err := p.parseKeyValue(s)
+		if err != nil {
+			return nil, nil, &ParseError{Line: line, Err: err}
+		}
+		if warning != "" {
+			p.warnings = append(p.warnings, fmt.Sprintf("line %d: %s", line, warning))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.cfg.Validate(); err != nil {
+		return nil, nil, &ParseError{Line: line, Err: err}
+	}
+
+	return p.cfg, p.warnings, nil
+}
+
+func (p *wgQuickParser) parseSection(s string) error {
+	if !strings.HasSuffix(s, "]") {
+		return fmt.Errorf("invalid section %s", s)
+	}
+
+	section := strings.ToLower(strings.TrimSpace(s[1 : len(s)-1]))
+	switch section {
+	case sectionInterface:
+	case sectionPeer:
+		p.cfg.Peers = append(p.cfg.Peers, Peer{})
+	default:
+		return fmt.Errorf("unknown section %s", s)
+	}
+
+	p.section = section
+	p.seen = make(map[string]bool)
+
+	return nil
+}
+
+func (p *wgQuickParser) parseKeyValue(s string) (string, error) {
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		return "", fmt.Errorf("invalid line %q, expected key = value", s)
+	}
+
+	var (
+		key   = strings.TrimSpace(s[:i])
+		value = strings.TrimSpace(s[i+1:])
+		name  = strings.ToLower(key)
+	)
+
+	if key == "" {
+		return "", errors.New("key cannot be empty")
+	}
+	if value == "" {
+		return "", fmt.Errorf("value of %s cannot be empty", key)
+	}
+
+	switch p.section {
+	case sectionInterface:
+		if !isScriptKey(name) && p.seen[name] {
+			return "", fmt.Errorf("duplicate key %s", key)
+		}
+
+		p.seen[name] = true
+		if isIgnoredKey(name) {
+			return fmt.Sprintf("ignoring unsupported interface key %s", key), nil
+		}
+
+		return "", p.parseInterface(key, name, value)
+	case sectionPeer:
+		if p.seen[name] {
+			return "", fmt.Errorf("duplicate key %s", key)
+		}
+
+		p.seen[name] = true
+		return "", p.parsePeer(key, name, value, &p.cfg.Peers[len(p.cfg.Peers)-1])
+	default:
+		return "", fmt.Errorf("key %s is outside of a section", key)
+	}
+}
+
+func (p *wgQuickParser) parseInterface(key, name, value string) (err error) {
+	v := &p.cfg.Interface
+	switch name {
+	case "privatekey":
+		k, err := ParseKey(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", key)
+		}
+
+		v.PrivateKey = *k
+	case "address":
+		for _, s := range splitList(value) {
+			item, err := parseAddress(s)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s %s", key, s)
+			}
+
+			v.Addresses = append(v.Addresses, item)
+		}
+	case "listenport":
+		v.ListenPort, err = parseUint16(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", key)
+		}
+	case "mtu":
+		v.MTU, err = parseUint16(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", key)
+		}
+	case "dns":
+		for _, s := range splitList(value) {
+			if ip := net.ParseIP(s); ip != nil {
+				v.DNS = append(v.DNS, normalizeIP(ip))
+				continue
+			}
+
+			v.DNSSearch = append(v.DNSSearch, s)
+		}
+	case "preup":
+		v.PreUp = joinScript(v.PreUp, value)
+	case "postup":
+		v.PostUp = joinScript(v.PostUp, value)
+	case "predown":
+		v.PreDown = joinScript(v.PreDown, value)
+	case "postdown":
+		v.PostDown = joinScript(v.PostDown, value)
+	default:
+		return fmt.Errorf("unsupported interface key %s", key)
+	}
+
+	return nil
+}
+
+func (p *wgQuickParser) parsePeer(key, name, value string, v *Peer) (err error) {
+	switch name {
+	case "publickey":
+		k, err := ParseKey(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", key)
+		}
+
+		v.PublicKey = *k
+	case "presharedkey":
+		k, err := ParseKey(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", key)
+		}
+
+		v.PresharedKey = *k
+	case "allowedips":
+		for _, s := range splitList(value) {
+			item, err := parseAddress(s)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s %s", key, s)
+			}
+
+			v.AllowedIPs = append(v.AllowedIPs, item)
+		}
+	case "endpoint":
+		v.Endpoint, err = ParseEndpoint(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", key)
+		}
+		if v.Endpoint.Host == "" {
+			return fmt.Errorf("invalid %s, host cannot be empty", key)
+		}
+	case "persistentkeepalive":
+		if strings.ToLower(value) == "off" {
+			v.PersistentKeepalive = 0
+			return nil
+		}
+
+		v.PersistentKeepalive, err = parseUint16(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", key)
+		}
+	default:
+		return fmt.Errorf("unsupported peer key %s", key)
+	}
+
+	return nil
+}
+
+func (c *Config) HasScripts() bool {
+	return c.Interface.PreUp != "" || c.Interface.PostUp != "" ||
+		c.Interface.PreDown != "" || c.Interface.PostDown != ""
+}
+
+func (c *Config) Validate() error {
+	if c.Interface.PrivateKey.IsZero() {
+		return errors.New("interface private key cannot be empty")
+	}
+
+	for i, peer := range c.Peers {
+		if peer.PublicKey.IsZero() {
+			return fmt.Errorf("public key of peer %d cannot be empty", i+1)
+		}
+	}
+
+	return nil
+}
+
+func isScriptKey(name string) bool {
+	return name == "preup" || name == "postup" || name == "predown" || name == "postdown"
+}
+
+func isIgnoredKey(name string) bool {
+	return name == "table" || name == "fwmark" || name == "saveconfig"
+}
+
+func joinScript(s, v string) string {
+	if s == "" {
+		return v
+	}
+
+	return s + "; " + v
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func parseUint16(s string) (uint16, error) {
+	v, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint16(v), nil
+}
+
+func normalizeIP(ip net.IP) net.IP {
+	if v := ip.To4(); v != nil {
+		return v
+	}
+
+	return ip
+}
+
+func parseAddress(s string) (IPNet, error) {
+	if strings.IndexByte(s, '/') >= 0 {
+		return ParseIPNet(s)
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return IPNet{}, errors.New("invalid ip address")
+	}
+	if v := ip.To4(); v != nil {
+		return IPNet{IP: v, Net: 8 * net.IPv4len}, nil
+	}
+
+	return IPNet{IP: ip, Net: 8 * net.IPv6len}, nil
+}
@@ -0,0 +1,250 @@
+package types_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/sentinel-official/cli-client/services/wireguard/types"
+)
+
+func newInfo() []byte {
+	info := make([]byte, 58)
+	copy(info[0:4], net.ParseIP("10.8.0.2").To4())
+	copy(info[4:20], net.ParseIP("fd00::2"))
+	copy(info[20:24], net.ParseIP("203.0.113.7").To4())
+	info[24], info[25] = 0xca, 0x6c
+	for i := 26; i < 58; i++ {
+		info[i] = byte(i)
+	}
+
+	return info
+}
+
+func newKey(t *testing.T) *types.Key {
+	key, err := types.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestWgQuickRoundTrip(t *testing.T) {
+	include, err := types.ParseIPNets([]string{"10.0.0.0/8", "2001:db8::/32"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exclude, err := types.ParseIPNets([]string{"10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	presharedKey, err := types.NewPresharedKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configs := []*types.Config{
+		types.NewConfigFromInfo("wg0", newInfo(), newKey(t), 51820, true, nil, nil, nil),
+		types.NewConfigFromInfo("wg1", newInfo(), newKey(t), 0, false, []net.IP{net.ParseIP("1.1.1.1"), net.ParseIP("2606:4700::1111")}, include, exclude),
+	}
+
+	cfg := types.NewConfigFromInfo("wg2", newInfo(), newKey(t), 40000, true, nil, nil, nil)
+	cfg.Interface.MTU = 1280
+	cfg.Interface.DNSSearch = []string{"example.com", "corp.internal"}
+	cfg.Interface.PreUp = "echo pre-up"
+	cfg.Interface.PostUp = "echo post-up"
+	cfg.Interface.PreDown = "echo pre-down"
+	cfg.Interface.PostDown = "echo post-down"
+	cfg.Peers[0].PresharedKey = *presharedKey
+	cfg.Peers[0].Endpoint = types.Endpoint{Host: "2001:db8::1", Port: 51820}
+	cfg.Peers = append(cfg.Peers, types.Peer{PublicKey: *newKey(t).Public()})
+	configs = append(configs, cfg)
+
+	for _, cfg := range configs {
+		t.Run(cfg.Name, func(t *testing.T) {
+			s := cfg.ToWgQuick()
+
+			parsed, err := types.ParseWgQuick([]byte(s))
+			if err != nil {
+				t.Fatalf("failed to parse:\n%s\nerror: %s", s, err)
+			}
+
+			if v := parsed.ToWgQuick(); v != s {
+				t.Fatalf("round trip mismatch:\n%s\n---\n%s", s, v)
+			}
+			if parsed.Interface.PrivateKey != cfg.Interface.PrivateKey {
+				t.Fatal("private key mismatch")
+			}
+			if len(parsed.Peers) != len(cfg.Peers) {
+				t.Fatalf("got %d peers, want %d", len(parsed.Peers), len(cfg.Peers))
+			}
+			for i := range cfg.Peers {
+				if parsed.Peers[i].PublicKey != cfg.Peers[i].PublicKey {
+					t.Fatalf("public key mismatch of peer %d", i)
+				}
+				if parsed.Peers[i].Endpoint != cfg.Peers[i].Endpoint {
+					t.Fatalf("endpoint mismatch of peer %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestParseWgQuick(t *testing.T) {
+	var (
+		privateKey = newKey(t)
+		publicKey  = newKey(t).Public()
+		data       = strings.Join([]string{
+			"# exported by a router",
+			"[interface]",
+			"privatekey = " + privateKey.String(),
+			"Address = 10.8.0.2, fd00::2 # bare addresses",
+			"DNS = 10.8.0.1,example.com",
+			"PostUp = echo one",
+			"PostUp = echo two",
+			"",
+			"[Peer]",
+			"PublicKey = " + publicKey.String(),
+			"AllowedIPs = 0.0.0.0/0, ::/0",
+			"Endpoint = node.example.com:51820",
+			"PersistentKeepalive = off",
+		}, "\n")
+	)
+
+	cfg, err := types.ParseWgQuick([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Interface.PrivateKey != *privateKey {
+		t.Fatal("private key mismatch")
+	}
+	if len(cfg.Interface.Addresses) != 2 || cfg.Interface.Addresses[0].String() != "10.8.0.2/32" ||
+		cfg.Interface.Addresses[1].String() != "fd00::2/128" {
+		t.Fatalf("unexpected addresses %v", cfg.Interface.Addresses)
+	}
+	if len(cfg.Interface.DNS) != 1 || !cfg.Interface.DNS[0].Equal(net.ParseIP("10.8.0.1")) {
+		t.Fatalf("unexpected dns %v", cfg.Interface.DNS)
+	}
+	if len(cfg.Interface.DNSSearch) != 1 || cfg.Interface.DNSSearch[0] != "example.com" {
+		t.Fatalf("unexpected dns search %v", cfg.Interface.DNSSearch)
+	}
+	if cfg.Interface.PostUp != "echo one; echo two" {
+		t.Fatalf("unexpected post up %q", cfg.Interface.PostUp)
+	}
+	if len(cfg.Peers) != 1 || cfg.Peers[0].PublicKey != *publicKey {
+		t.Fatal("peer mismatch")
+	}
+	if cfg.Peers[0].Endpoint != (types.Endpoint{Host: "node.example.com", Port: 51820}) {
+		t.Fatalf("unexpected endpoint %v", cfg.Peers[0].Endpoint)
+	}
+	if cfg.Peers[0].PersistentKeepalive != 0 {
+		t.Fatal("persistent keepalive must be off")
+	}
+}
+
+func TestParseWgQuickErrors(t *testing.T) {
+	var (
+		privateKey = "PrivateKey = " + newKey(t).String()
+		publicKey  = "PublicKey = " + newKey(t).Public().String()
+	)
+
+	tests := []struct {
+		name string
+		data []string
+		line int
+		msg  string
+	}{
+		{"key outside section", []string{privateKey}, 1, "outside of a section"},
+		{"unknown section", []string{"[Interface]", privateKey, "[Server]"}, 3, "unknown section"},
+		{"unterminated section", []string{"[Interface"}, 1, "invalid section"},
+		{"missing equals", []string{"[Interface]", "PrivateKey"}, 2, "expected key = value"},
+		{"empty value", []string{"[Interface]", "PrivateKey ="}, 2, "cannot be empty"},
+		{"invalid key", []string{"[Interface]", "PrivateKey = abc"}, 2, "invalid PrivateKey"},
+		{"short key", []string{"[Interface]", "PrivateKey = AAAA"}, 2, "key length"},
+		{"invalid address", []string{"[Interface]", privateKey, "Address = 10.8.0.300/32"}, 3, "invalid Address"},
+		{"invalid port", []string{"[Interface]", privateKey, "ListenPort = 70000"}, 3, "invalid ListenPort"},
+		{"invalid mtu", []string{"[Interface]", privateKey, "MTU = big"}, 3, "invalid MTU"},
+		{"unsupported key", []string{"[Interface]", privateKey, "Foo = bar"}, 3, "unsupported interface key"},
+		{"duplicate key", []string{"[Interface]", privateKey, privateKey}, 3, "duplicate key"},
+		{"duplicate interface", []string{"[Interface]", privateKey, "[Interface]"}, 3, "duplicate interface"},
+		{"invalid endpoint", []string{"[Interface]", privateKey, "", "[Peer]", publicKey, "Endpoint = 1.2.3.4"}, 6, "invalid Endpoint"},
+		{"invalid allowed ips", []string{"[Interface]", privateKey, "[Peer]", publicKey, "AllowedIPs = 0.0.0.0/33"}, 5, "invalid AllowedIPs"},
+		{"invalid keepalive", []string{"[Interface]", privateKey, "[Peer]", publicKey, "PersistentKeepalive = -1"}, 5, "invalid PersistentKeepalive"},
+		{"unsupported peer key", []string{"[Interface]", privateKey, "[Peer]", "Foo = bar"}, 4, "unsupported peer key"},
+		{"missing private key", []string{"[Interface]", "ListenPort = 1"}, 2, "private key cannot be empty"},
+		{"missing public key", []string{"[Interface]", privateKey, "[Peer]", "Endpoint = 1.2.3.4:1", "# end"}, 5, "public key of peer 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := types.ParseWgQuick([]byte(strings.Join(tt.data, "\n")))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			var e *types.ParseError
+			if !errors.As(err, &e) {
+				t.Fatalf("error %T is not a *types.ParseError", err)
+			}
+			if e.Line != tt.line {
+				t.Fatalf("got line %d, want %d (%s)", e.Line, tt.line, err)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("error %q does not contain %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseWgQuickIgnoredKeys(t *testing.T) {
+	data := strings.Join([]string{
+		"[Interface]",
+		"PrivateKey = " + newKey(t).String(),
+		"Table = off",
+		"FwMark = 0x1234",
+		"SaveConfig = true",
+		"PostUp = ip rule add table 1234",
+	}, "\n")
+
+	cfg, warnings, err := types.ParseWgQuickWithWarnings([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 3 {
+		t.Fatalf("got warnings %q, want 3", warnings)
+	}
+	if !strings.HasPrefix(warnings[0], "line 3:") || !strings.Contains(warnings[0], "Table") {
+		t.Fatalf("unexpected warning %q", warnings[0])
+	}
+	if !cfg.HasScripts() {
+		t.Fatal("expected the config to have scripts")
+	}
+}
+
+func TestNewConfigFromInfoNodeResolver(t *testing.T) {
+	resolver := net.ParseIP("1.1.1.1")
+
+	cfg := types.NewConfigFromInfo("wg0", newInfo(), newKey(t), 0, true, []net.IP{resolver}, nil, nil)
+	if len(cfg.Interface.DNS) != 2 || !cfg.Interface.DNS[0].Equal(types.NodeResolver.IP) {
+		t.Fatalf("expected the node resolver first, got %v", cfg.Interface.DNS)
+	}
+
+	include, err := types.ParseIPNets([]string{"192.168.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg = types.NewConfigFromInfo("wg0", newInfo(), newKey(t), 0, false, []net.IP{resolver}, include, nil)
+	if len(cfg.Interface.DNS) != 1 || !cfg.Interface.DNS[0].Equal(resolver) {
+		t.Fatalf("expected only %s, got %v", resolver, cfg.Interface.DNS)
+	}
+	if types.ContainsIPNet(cfg.Peers[0].AllowedIPs, types.NodeResolver) {
+		t.Fatalf("expected no route to the node resolver, got %v", cfg.Peers[0].AllowedIPs)
+	}
+}
@@ -0,0 +1,338 @@
+package wireguard
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun/netstack"
+
+	"github.com/sentinel-official/cli-client/services/wireguard/types"
+	clienttypes "github.com/sentinel-official/cli-client/types"
+)
+
+var (
+	_ clienttypes.Service = (*Userspace)(nil)
+)
+
+var (
+	userspaceMutex   sync.Mutex
+	userspaceDevices = make(map[string]*userspaceDevice)
+)
+
+type userspaceDevice struct {
+	device   *device.Device
+	listener net.Listener
+}
+
+type Userspace struct {
+	cfg    *types.Config
+	info   []byte
+	home   string
+	listen string
+}
+
+func NewUserspace() *Userspace {
+	return &Userspace{}
+}
+
+func NewUserspaceService(cfg *types.Config, home, listen string) clienttypes.Service {
+	return NewUserspace().WithConfig(cfg).WithHome(home).WithListen(listen)
+}
+
+func (u *Userspace) WithConfig(v *types.Config) *Userspace { u.cfg = v; return u }
+func (u *Userspace) WithInfo(v []byte) *Userspace          { u.info = v; return u }
+func (u *Userspace) WithHome(v string) *Userspace          { u.home = v; return u }
+func (u *Userspace) WithListen(v string) *Userspace        { u.listen = v; return u }
+
+func (u *Userspace) Info() []byte { return u.info }
+
+func (u *Userspace) lookup() (*userspaceDevice, bool) {
+	userspaceMutex.Lock()
+	defer userspaceMutex.Unlock()
+
+	v, ok := userspaceDevices[u.cfg.Name]
+	return v, ok
+}
+
+func (u *Userspace) IsUp() bool {
+	_, ok := u.lookup()
+	return ok
+}
+
+func (u *Userspace) PreUp() error {
+	if u.IsUp() {
+		return fmt.Errorf("device %s is already running", u.cfg.Name)
+	}
+
+	return nil
+}
+
+func (u *Userspace) Up() error {
+	var (
+		addresses = make([]netip.Addr, 0, len(u.cfg.Interface.Addresses))
+		dns       = make([]netip.Addr, 0, len(u.cfg.Interface.DNS))
+		mtu       = DefaultMTU
+	)
+
+	for _, address := range u.cfg.Interface.Addresses {
+		addr, ok := netip.AddrFromSlice(address.IP)
+		if !ok {
+			return fmt.Errorf("invalid address %s", address.String())
+		}
+
+		addresses = append(addresses, addr.Unmap())
+	}
+
+	for _, ip := range u.cfg.Interface.DNS {
+		addr, ok := netip.AddrFromSlice(ip)
+		if !ok {
+			return fmt.Errorf("invalid dns %s", ip)
+		}
+
+		dns = append(dns, addr.Unmap())
+	}
+
+	if u.cfg.Interface.MTU > 0 {
+		mtu = int(u.cfg.Interface.MTU)
+	}
+
+	uapi, err := u.uapiConfig()
+	if err != nil {
+		return err
+	}
+
+	tun, tnet, err := netstack.CreateNetTUN(addresses, dns, mtu)
+	if err != nil {
+		return err
+	}
+
+	dev := device.NewDevice(
+		tun,
+		conn.NewDefaultBind(),
+		device.NewLogger(device.LogLevelError, fmt.Sprintf("(%s) ", u.cfg.Name)),
+	)
+
+	if err := dev.IpcSet(uapi); err != nil {
+		dev.Close()
+		return err
+	}
+	if err := dev.Up(); err != nil {
+		dev.Close()
+		return err
+	}
+
+	listener, err := net.Listen("tcp", u.listen)
+	if err != nil {
+		dev.Close()
+		return err
+	}
+
+	go serveProxy(listener, tnet.DialContext)
+
+	userspaceMutex.Lock()
+	defer userspaceMutex.Unlock()
+
+	userspaceDevices[u.cfg.Name] = &userspaceDevice{
+		device:   dev,
+		listener: listener,
+	}
+
+	return nil
+}
+
+func (u *Userspace) PostUp() error  { return nil }
+func (u *Userspace) PreDown() error { return nil }
+
+func (u *Userspace) Down() error {
+	userspaceMutex.Lock()
+	defer userspaceMutex.Unlock()
+
+	v, ok := userspaceDevices[u.cfg.Name]
+	if !ok {
+		return fmt.Errorf("device %s does not exist", u.cfg.Name)
+	}
+
+	delete(userspaceDevices, u.cfg.Name)
+
+	err := v.listener.Close()
+	v.device.Close()
+
+	return err
+}
+
+func (u *Userspace) PostDown() error { return nil }
+
+func (u *Userspace) ipcGet() (map[string][]string, error) {
+	v, ok := u.lookup()
+	if !ok {
+		return nil, fmt.Errorf("device %s does not exist", u.cfg.Name)
+	}
+
+	s, err := v.device.IpcGet()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		items   = make(map[string][]string)
+		scanner = bufio.NewScanner(strings.NewReader(s))
+	)
+
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		items[key] = append(items[key], value)
+	}
+
+	return items, scanner.Err()
+}
+
+func (u *Userspace) Transfer() (upload int64, download int64, err error) {
+	items, err := u.ipcGet()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, s := range items["rx_bytes"] {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		upload += v
+	}
+
+	for _, s := range items["tx_bytes"] {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		download += v
+	}
+
+	return upload, download, nil
+}
+
+func (u *Userspace) Stats() (*clienttypes.Stats, error) {
+	v, ok := u.lookup()
+	if !ok {
+		return nil, fmt.Errorf("device %s does not exist", u.cfg.Name)
+	}
+
+	s, err := v.device.IpcGet()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stats   = &clienttypes.Stats{}
+		peer    *clienttypes.PeerStats
+		sec     int64
+		scanner = bufio.NewScanner(strings.NewReader(s))
+	)
+
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		if key == "public_key" {
+			publicKey, err := hex.DecodeString(value)
+			if err != nil {
+				return nil, err
+			}
+
+			stats.Peers = append(stats.Peers, clienttypes.PeerStats{
+				PublicKey: base64.StdEncoding.EncodeToString(publicKey),
+			})
+
+			peer = &stats.Peers[len(stats.Peers)-1]
+			continue
+		}
+		if peer == nil {
+			continue
+		}
+
+		switch key {
+		case "endpoint":
+			peer.Endpoint = value
+		case "last_handshake_time_sec":
+			if sec, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, err
+			}
+		case "last_handshake_time_nsec":
+			nsec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if sec != 0 || nsec != 0 {
+				peer.LatestHandshake = time.Unix(sec, nsec)
+			}
+		case "rx_bytes":
+			if peer.RxBytes, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, err
+			}
+		case "tx_bytes":
+			if peer.TxBytes, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, err
+			}
+		case "persistent_keepalive_interval":
+			keepalive, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return nil, err
+			}
+
+			peer.PersistentKeepalive = uint16(keepalive)
+		}
+	}
+
+	return stats, scanner.Err()
+}
+
+func (u *Userspace) uapiConfig() (string, error) {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("private_key=%s\n", hex.EncodeToString(u.cfg.Interface.PrivateKey[:])))
+	output.WriteString(fmt.Sprintf("listen_port=%d\n", u.cfg.Interface.ListenPort))
+	output.WriteString("replace_peers=true\n")
+
+	for _, peer := range u.cfg.Peers {
+		output.WriteString(fmt.Sprintf("public_key=%s\n", hex.EncodeToString(peer.PublicKey[:])))
+		if !peer.PresharedKey.IsZero() {
+			output.WriteString(fmt.Sprintf("preshared_key=%s\n", hex.EncodeToString(peer.PresharedKey[:])))
+		}
+
+		if !peer.Endpoint.IsEmpty() {
+			endpoint, err := net.ResolveUDPAddr("udp", peer.Endpoint.String())
+			if err != nil {
+				return "", err
+			}
+
+			output.WriteString(fmt.Sprintf("endpoint=%s\n", endpoint.String()))
+		}
+
+		output.WriteString(fmt.Sprintf("persistent_keepalive_interval=%d\n", peer.PersistentKeepalive))
+		output.WriteString("replace_allowed_ips=true\n")
+		for _, ip := range peer.AllowedIPs {
+			raw := ip.Raw()
+			output.WriteString(fmt.Sprintf("allowed_ip=%s\n", raw.String()))
+		}
+	}
+
+	return output.String(), nil
+}
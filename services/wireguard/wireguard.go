@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alessio/shellescape"
 
@@ -14,6 +15,10 @@ import (
 	clienttypes "github.com/sentinel-official/cli-client/types"
 )
 
+const (
+	DefaultMTU = 1420
+)
+
 var (
 	_ clienttypes.Service = (*WireGuard)(nil)
 )
@@ -102,3 +107,70 @@ func (w *WireGuard) Transfer() (u int64, d int64, err error) {
 
 	return 0, 0, nil
 }
+
+func (w *WireGuard) Stats() (*clienttypes.Stats, error) {
+	iFace, err := w.RealInterface()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := exec.Command(w.ExecFile("wg"), strings.Split(
+		fmt.Sprintf("show %s dump", shellescape.Quote(iFace)), " ")...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDump(output)
+}
+
+func parseDump(output []byte) (*clienttypes.Stats, error) {
+	stats := &clienttypes.Stats{}
+
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		columns := strings.Split(strings.TrimSpace(line), "\t")
+		if len(columns) != 8 {
+			continue
+		}
+
+		handshake, err := strconv.ParseInt(columns[4], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		rx, err := strconv.ParseInt(columns[5], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		tx, err := strconv.ParseInt(columns[6], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		peer := clienttypes.PeerStats{
+			PublicKey: columns[0],
+			RxBytes:   rx,
+			TxBytes:   tx,
+		}
+
+		if columns[2] != "(none)" {
+			peer.Endpoint = columns[2]
+		}
+		if handshake != 0 {
+			peer.LatestHandshake = time.Unix(handshake, 0)
+		}
+		if columns[7] != "off" {
+			keepalive, err := strconv.ParseUint(columns[7], 10, 16)
+			if err != nil {
+				return nil, err
+			}
+
+			peer.PersistentKeepalive = uint16(keepalive)
+		}
+
+		stats.Peers = append(stats.Peers, peer)
+	}
+
+	return stats, nil
+}
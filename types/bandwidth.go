@@ -2,6 +2,8 @@ package types
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	hubtypes "github.com/sentinel-official/hub/types"
 )
@@ -14,6 +16,36 @@ const (
 	TB       = 1e3 * GB
 )
 
+func ParseBytes(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for _, item := range []struct {
+		unit  string
+		value int64
+	}{
+		{"TB", TB},
+		{"GB", GB},
+		{"MB", MB},
+		{"KB", KB},
+		{"B", B},
+	} {
+		if !strings.HasSuffix(s, item.unit) {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, item.unit)), 64)
+		if err != nil {
+			return 0, err
+		}
+		if v < 0 {
+			return 0, fmt.Errorf("invalid bytes %s", s)
+		}
+
+		return int64(v * float64(item.value)), nil
+	}
+
+	return strconv.ParseInt(s, 10, 64)
+}
+
 func ToReadableBytes(bytes int64, decimals int) (out string) {
 	var (
 		i    int64
@@ -15,35 +15,81 @@ import (
 )
 
 const (
-	FlagAccount        = "account"
-	FlagAddress        = "address"
-	FlagBroadcastMode  = "broadcast-mode"
-	FlagChainID        = "chain-id"
-	FlagCoinType       = "coin-type"
-	FlagDescription    = "description"
-	FlagFrom           = "from"
-	FlagGas            = "gas"
-	FlagGasPrices      = "gas-prices"
-	FlagHome           = "home"
-	FlagIdentity       = "identity"
-	FlagIndex          = "index"
-	FlagKeyringBackend = "keyring-backend"
-	FlagKeyringHome    = "keyring-home"
-	FlagListen         = "listen"
-	FlagMemo           = "memo"
-	FlagName           = "name"
-	FlagProvider       = "provider"
-	FlagRating         = "rating"
-	FlagRecover        = "recover"
-	FlagResolver       = "resolver"
-	FlagRPCAddress     = "rpc-address"
-	FlagServiceHome    = "service.home"
-	FlagStatus         = "status"
-	FlagTimeout        = "timeout"
-	FlagTTY            = "tty"
-	FlagWebsite        = "website"
-	FlagWithKeyring    = "with-keyring"
-	FlagWithService    = "with-service"
+	FlagAccount           = "account"
+	FlagAddress           = "address"
+	FlagAllowConfigHooks  = "allow-config-hooks"
+	FlagAllowedHosts      = "allowed-hosts"
+	FlagAllowedOrigins    = "allowed-origins"
+	FlagAuto              = "auto"
+	FlagBroadcastMode     = "broadcast-mode"
+	FlagChainID           = "chain-id"
+	FlagCoinType          = "coin-type"
+	FlagCountry           = "country"
+	FlagDescription       = "description"
+	FlagDNSSearch         = "dns-search"
+	FlagEndSession        = "end-session"
+	FlagExclude           = "exclude"
+	FlagFollow            = "follow"
+	FlagFrom              = "from"
+	FlagGas               = "gas"
+	FlagGasPrices         = "gas-prices"
+	FlagHome              = "home"
+	FlagIdentity          = "identity"
+	FlagInclude           = "include"
+	FlagIndex             = "index"
+	FlagInterface         = "interface"
+	FlagKeepalive         = "keepalive"
+	FlagKeepSession       = "keep-session"
+	FlagKeyringBackend    = "keyring-backend"
+	FlagKeyringHome       = "keyring-home"
+	FlagKeyringUIDs       = "keyring-uids"
+	FlagKillSwitch        = "kill-switch"
+	FlagLimitInterval     = "limit-interval"
+	FlagListen            = "listen"
+	FlagMaxBytes          = "max-bytes"
+	FlagMaxDuration       = "max-duration"
+	FlagMaxPrice          = "max-price"
+	FlagMemo              = "memo"
+	FlagMode              = "mode"
+	FlagMTU               = "mtu"
+	FlagName              = "name"
+	FlagNode              = "node"
+	FlagNoNodeResolver    = "no-node-resolver"
+	FlagOnStart           = "on-start"
+	FlagOutput            = "output"
+	FlagPlan              = "plan"
+	FlagProvider          = "provider"
+	FlagProxyListen       = "proxy-listen"
+	FlagQueryUIDs         = "query-uids"
+	FlagRating            = "rating"
+	FlagReason            = "reason"
+	FlagReconnectInterval = "reconnect-interval"
+	FlagReconnectNodes    = "reconnect-nodes"
+	FlagReconnectRetries  = "reconnect-retries"
+	FlagReconnectTimeout  = "reconnect-timeout"
+	FlagRecover           = "recover"
+	FlagResolver          = "resolver"
+	FlagRPCAddress        = "rpc-address"
+	FlagSampleInterval    = "sample-interval"
+	FlagServiceBackend    = "service-backend"
+	FlagServiceHome       = "service.home"
+	FlagServiceUIDs       = "service-uids"
+	FlagSince             = "since"
+	FlagSocketMode        = "socket-mode"
+	FlagSocketOwner       = "socket-owner"
+	FlagStatus            = "status"
+	FlagSubscription      = "subscription"
+	FlagTimeout           = "timeout"
+	FlagTLS               = "tls"
+	FlagTLSCert           = "tls-cert"
+	FlagTLSKey            = "tls-key"
+	FlagTTY               = "tty"
+	FlagUntil             = "until"
+	FlagWebsite           = "website"
+	FlagWireGuardBackend  = "wireguard-backend"
+	FlagWithKeyring       = "with-keyring"
+	FlagWithQuery         = "with-query"
+	FlagWithService       = "with-service"
 )
 
 func addKeyringFlagsToCmd(cmd *cobra.Command) {
@@ -51,8 +97,12 @@ func addKeyringFlagsToCmd(cmd *cobra.Command) {
 	cmd.Flags().String(FlagKeyringHome, Home, "home directory of the keyring")
 }
 
-func addQueryFlagsToCmd(cmd *cobra.Command) {
+func addOptionalQueryFlagsToCmd(cmd *cobra.Command) {
 	cmd.Flags().String(FlagRPCAddress, "", "tendermint RPC interface address for this chain")
+}
+
+func addQueryFlagsToCmd(cmd *cobra.Command) {
+	addOptionalQueryFlagsToCmd(cmd)
 	_ = cmd.MarkFlagRequired(FlagRPCAddress)
 }
 
@@ -61,16 +111,20 @@ func addServiceFlagsToCmd(cmd *cobra.Command) {
 }
 
 func addTimeoutFlagsToCmd(cmd *cobra.Command) {
-	cmd.Flags().Duration(FlagTimeout, 15*time.Second, "time limit for requests made by the HTTP client")
+	cmd.Flags().Duration(FlagTimeout, Timeout, "time limit for requests made by the HTTP client")
 }
 
-func addTxFlagsToCmd(cmd *cobra.Command) {
+func addOptionalTxFlagsToCmd(cmd *cobra.Command) {
 	cmd.Flags().String(FlagBroadcastMode, flags.BroadcastBlock, "transaction broadcasting mode (async|block|sync)")
 	cmd.Flags().String(FlagChainID, "", "chain identity of the network")
 	cmd.Flags().String(FlagFrom, "", "name or address of private key with which to sign")
 	cmd.Flags().Uint64(FlagGas, flags.DefaultGasLimit, "gas limit to set per-transaction")
 	cmd.Flags().String(FlagGasPrices, "", "gas prices in decimal format to determine the transaction fee")
 	cmd.Flags().String(FlagMemo, "", "memo to send along with transaction")
+}
+
+func addTxFlagsToCmd(cmd *cobra.Command) {
+	addOptionalTxFlagsToCmd(cmd)
 	_ = cmd.MarkFlagRequired(FlagChainID)
 	_ = cmd.MarkFlagRequired(FlagFrom)
 }
@@ -103,6 +157,13 @@ func AddTxFlagsToCmd(cmd *cobra.Command) {
 	addTxFlagsToCmd(cmd)
 }
 
+func AddOptionalTxFlagsToCmd(cmd *cobra.Command) {
+	addKeyringFlagsToCmd(cmd)
+	addOptionalQueryFlagsToCmd(cmd)
+	addTimeoutFlagsToCmd(cmd)
+	addOptionalTxFlagsToCmd(cmd)
+}
+
 func GetAccAddressFromCmd(cmd *cobra.Command) (sdk.AccAddress, error) {
 	s, err := cmd.Flags().GetString(FlagAddress)
 	if err != nil {
@@ -140,12 +201,7 @@ func GetStatusFromCmd(cmd *cobra.Command) (hubtypes.Status, error) {
 	return hubtypes.StatusFromString(s), nil
 }
 
-func GetHTTPClientFromCmd(cmd *cobra.Command) (c http.Client, err error) {
-	timeout, err := cmd.Flags().GetDuration(FlagTimeout)
-	if err != nil {
-		return c, err
-	}
-
+func NewHTTPClient(timeout time.Duration) http.Client {
 	return http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
@@ -153,5 +209,17 @@ func GetHTTPClientFromCmd(cmd *cobra.Command) (c http.Client, err error) {
 			},
 		},
 		Timeout: timeout,
+	}
+}
+
+func GetHTTPClientFromCmd(cmd *cobra.Command) (c http.Client, err error) {
+	timeout, err := cmd.Flags().GetDuration(FlagTimeout)
+	if err != nil {
+		return c, err
+	}
+
+	return http.Client{
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		Timeout:   timeout,
 	}, nil
 }
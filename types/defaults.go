@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+type Defaults map[string][]string
+
+func DefaultsFilePath(home string) string {
+	return filepath.Join(home, DefaultsFilename)
+}
+
+func LoadDefaults(path string) (Defaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Defaults{}, nil
+		}
+
+		return nil, err
+	}
+
+	v := Defaults{}
+	if len(data) == 0 {
+		return v, nil
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (d Defaults) SaveToPath(path string) error {
+	bytes, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, bytes, 0600)
+}
+
+func (d Defaults) Keys() []string {
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (d Defaults) ApplyToCmd(cmd *cobra.Command) error {
+	for _, key := range d.Keys() {
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil || flag.Changed {
+			continue
+		}
+
+		for _, value := range d[key] {
+			if err := cmd.Flags().Set(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
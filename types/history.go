@@ -0,0 +1,145 @@
+package types
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	HistoryReasonCleanup    = "cleanup"
+	HistoryReasonDisconnect = "disconnect"
+	HistoryReasonFailover   = "failover"
+	HistoryReasonLimit      = "limit"
+	HistoryReasonShutdown   = "shutdown"
+)
+
+type HistoryRecord struct {
+	Name         string    `json:"name"`
+	Type         uint64    `json:"type"`
+	ID           uint64    `json:"id"`
+	Node         string    `json:"node"`
+	Subscription uint64    `json:"subscription"`
+	From         string    `json:"from"`
+	StartAt      time.Time `json:"start_at"`
+	EndAt        time.Time `json:"end_at"`
+	Upload       int64     `json:"upload"`
+	Download     int64     `json:"download"`
+	Reason       string    `json:"reason"`
+	Errors       []string  `json:"errors,omitempty"`
+}
+
+type HistoryRecords []HistoryRecord
+
+func AppendHistoryRecord(path string, record HistoryRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+func LoadHistoryRecords(path string) (HistoryRecords, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var (
+		items   HistoryRecords
+		scanner = bufio.NewScanner(file)
+	)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var item HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return nil, fmt.Errorf("invalid history record at line %d: %w", line, err)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, scanner.Err()
+}
+
+func (h HistoryRecords) Filter(fn func(HistoryRecord) bool) HistoryRecords {
+	var items HistoryRecords
+	for _, item := range h {
+		if fn(item) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func (h HistoryRecords) WriteJSON(w io.Writer) error {
+	if h == nil {
+		h = HistoryRecords{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(h)
+}
+
+func (h HistoryRecords) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(
+		[]string{
+			"name", "type", "id", "node", "subscription", "from",
+			"start_at", "end_at", "upload", "download", "reason", "errors",
+		},
+	); err != nil {
+		return err
+	}
+
+	for _, item := range h {
+		if err := writer.Write(
+			[]string{
+				item.Name,
+				fmt.Sprintf("%d", item.Type),
+				fmt.Sprintf("%d", item.ID),
+				item.Node,
+				fmt.Sprintf("%d", item.Subscription),
+				item.From,
+				item.StartAt.Format(time.RFC3339),
+				item.EndAt.Format(time.RFC3339),
+				fmt.Sprintf("%d", item.Upload),
+				fmt.Sprintf("%d", item.Download),
+				item.Reason,
+				strings.Join(item.Errors, "; "),
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
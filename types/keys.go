@@ -3,12 +3,55 @@ package types
 import (
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const (
-	APIPathPrefix  = "/api/v1"
-	StatusFilename = "status.json"
-	Listen         = "127.0.0.1:11112"
+	APIPathPrefix    = "/api/v1"
+	ConfigDirname    = "config"
+	DefaultsFilename = "defaults.json"
+	StatusDirname    = "status"
+	HistoryFilename  = "history.jsonl"
+	LegacyStatusFile = "status.json"
+	TLSCertFilename  = "tls.crt"
+	TLSKeyFilename   = "tls.key"
+	TokenFilename    = "token.txt"
+	URLFilename      = "url.txt"
+	Listen           = "127.0.0.1:11112"
+	Timeout          = 15 * time.Second
+	UnixScheme       = "unix"
+
+	DefaultConnection     = "default"
+	DefaultExecConnection = "exec"
+
+	ServiceTypeWireGuard = 1
+	ServiceTypeV2Ray     = 2
+
+	ServiceModeTunnel    = "tunnel"
+	ServiceModeProxy     = "proxy"
+	ServiceModeNamespace = "namespace"
+	DefaultProxyListen   = "127.0.0.1:1080"
+
+	ServiceBackendNamespace = "netns"
+	ServiceBackendNetlink   = "netlink"
+	ServiceBackendUserspace = "userspace"
+	ServiceBackendV2Ray     = "v2ray"
+	ServiceBackendWgQuick   = "wg-quick"
+
+	OnStartRestore = "restore"
+	OnStartCleanup = "cleanup"
+	OnStartIgnore  = "ignore"
+
+	ReconnectInterval = 15 * time.Second
+	ReconnectRetries  = 3
+	ReconnectTimeout  = 3 * time.Minute
+
+	TokenLength = 32
+
+	TLSCertificateValidity = 10 * 365 * 24 * time.Hour
+
+	LimitInterval  = 5 * time.Second
+	SampleInterval = 1 * time.Second
 )
 
 var (
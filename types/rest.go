@@ -64,3 +64,9 @@ func (r *RestResponseWriter) WriteHeader(status int) {
 	r.ResponseWriter.WriteHeader(status)
 	r.Status = status
 }
+
+func (r *RestResponseWriter) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
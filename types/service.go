@@ -2,7 +2,17 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ConnectionNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
 )
 
 type Service interface {
@@ -15,19 +25,108 @@ type Service interface {
 	Down() error
 	PostDown() error
 	Transfer() (int64, int64, error)
+	Stats() (*Stats, error)
+}
+
+type PeerStats struct {
+	PublicKey           string    `json:"public_key,omitempty"`
+	Endpoint            string    `json:"endpoint,omitempty"`
+	LatestHandshake     time.Time `json:"latest_handshake"`
+	RxBytes             int64     `json:"rx_bytes"`
+	TxBytes             int64     `json:"tx_bytes"`
+	PersistentKeepalive uint16    `json:"persistent_keepalive"`
+}
+
+type Stats struct {
+	Peers []PeerStats `json:"peers"`
+}
+
+func (s *Stats) LatestHandshake() (t time.Time) {
+	for _, peer := range s.Peers {
+		if peer.LatestHandshake.After(t) {
+			t = peer.LatestHandshake
+		}
+	}
+
+	return t
+}
+
+func (s *Stats) Endpoint() string {
+	var (
+		endpoint string
+		t        time.Time
+	)
+
+	for _, peer := range s.Peers {
+		if endpoint == "" || peer.LatestHandshake.After(t) {
+			endpoint, t = peer.Endpoint, peer.LatestHandshake
+		}
+	}
+
+	return endpoint
 }
 
 type ServiceStatus struct {
-	ID    uint64 `json:"id"`
-	IFace string `json:"iface"`
+	Name         string       `json:"name"`
+	Type         uint64       `json:"type"`
+	ID           uint64       `json:"id"`
+	Backend      string       `json:"backend,omitempty"`
+	Mode         string       `json:"mode,omitempty"`
+	IFace        string       `json:"iface"`
+	Proxy        string       `json:"proxy,omitempty"`
+	Namespace    string       `json:"namespace,omitempty"`
+	Endpoint     string       `json:"endpoint"`
+	Include      []string     `json:"include,omitempty"`
+	Exclude      []string     `json:"exclude,omitempty"`
+	KillSwitch   bool         `json:"kill_switch"`
+	Node         string       `json:"node,omitempty"`
+	Subscription uint64       `json:"subscription,omitempty"`
+	From         string       `json:"from,omitempty"`
+	StartAt      time.Time    `json:"start_at"`
+	Upload       int64        `json:"upload,omitempty"`
+	Download     int64        `json:"download,omitempty"`
+	Limits       *LimitStatus `json:"limits,omitempty"`
 }
 
 func NewServiceStatus() *ServiceStatus {
 	return &ServiceStatus{}
 }
 
-func (s *ServiceStatus) WithID(v uint64) *ServiceStatus    { s.ID = v; return s }
-func (s *ServiceStatus) WithIFace(v string) *ServiceStatus { s.IFace = v; return s }
+func (s *ServiceStatus) WithName(v string) *ServiceStatus         { s.Name = v; return s }
+func (s *ServiceStatus) WithType(v uint64) *ServiceStatus         { s.Type = v; return s }
+func (s *ServiceStatus) WithProxy(v string) *ServiceStatus        { s.Proxy = v; return s }
+func (s *ServiceStatus) WithID(v uint64) *ServiceStatus           { s.ID = v; return s }
+func (s *ServiceStatus) WithBackend(v string) *ServiceStatus      { s.Backend = v; return s }
+func (s *ServiceStatus) WithMode(v string) *ServiceStatus         { s.Mode = v; return s }
+func (s *ServiceStatus) WithIFace(v string) *ServiceStatus        { s.IFace = v; return s }
+func (s *ServiceStatus) WithNamespace(v string) *ServiceStatus    { s.Namespace = v; return s }
+func (s *ServiceStatus) WithEndpoint(v string) *ServiceStatus     { s.Endpoint = v; return s }
+func (s *ServiceStatus) WithInclude(v []string) *ServiceStatus    { s.Include = v; return s }
+func (s *ServiceStatus) WithExclude(v []string) *ServiceStatus    { s.Exclude = v; return s }
+func (s *ServiceStatus) WithKillSwitch(v bool) *ServiceStatus     { s.KillSwitch = v; return s }
+func (s *ServiceStatus) WithNode(v string) *ServiceStatus         { s.Node = v; return s }
+func (s *ServiceStatus) WithSubscription(v uint64) *ServiceStatus { s.Subscription = v; return s }
+func (s *ServiceStatus) WithFrom(v string) *ServiceStatus         { s.From = v; return s }
+func (s *ServiceStatus) WithStartAt(v time.Time) *ServiceStatus   { s.StartAt = v; return s }
+func (s *ServiceStatus) WithLimits(v *LimitStatus) *ServiceStatus { s.Limits = v; return s }
+func (s *ServiceStatus) WithUpload(v int64) *ServiceStatus        { s.Upload = v; return s }
+func (s *ServiceStatus) WithDownload(v int64) *ServiceStatus      { s.Download = v; return s }
+
+func (s *ServiceStatus) IsV2Ray() bool {
+	return s.Type == ServiceTypeV2Ray
+}
+
+func (s *ServiceStatus) IsProxy() bool {
+	return s.Mode == ServiceModeProxy
+}
+
+func (s *ServiceStatus) IsNamespace() bool {
+	return s.Mode == ServiceModeNamespace
+}
+
+func (s *ServiceStatus) IsDefaultRoute() bool {
+	return !s.IsV2Ray() && !s.IsProxy() && !s.IsNamespace() && len(s.Include) == 0
+}
 
 func (s *ServiceStatus) LoadFromPath(path string) error {
 	if _, err := os.Stat(path); err != nil {
@@ -51,5 +150,156 @@ func (s *ServiceStatus) SaveToPath(path string) error {
 		return err
 	}
 
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
 	return os.WriteFile(path, bytes, 0600)
 }
+
+func ServiceStatusFilePath(home, name string) string {
+	return filepath.Join(home, StatusDirname, fmt.Sprintf("%s.json", name))
+}
+
+func LoadServiceStatuses(home string) ([]*ServiceStatus, error) {
+	entries, err := os.ReadDir(filepath.Join(home, StatusDirname))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var items []*ServiceStatus
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		var (
+			name = strings.TrimSuffix(entry.Name(), ".json")
+			item = NewServiceStatus()
+		)
+
+		if err := item.LoadFromPath(ServiceStatusFilePath(home, name)); err != nil {
+			return nil, err
+		}
+		if item.IFace == "" {
+			continue
+		}
+
+		items = append(items, item.WithName(name))
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items, nil
+}
+
+const (
+	ReconnectStateConnected    = "connected"
+	ReconnectStateReconnecting = "reconnecting"
+)
+
+type ReconnectAttempt struct {
+	Node    string    `json:"node"`
+	Session uint64    `json:"session"`
+	Time    time.Time `json:"time"`
+	Error   string    `json:"error,omitempty"`
+}
+
+type ReconnectStatus struct {
+	State    string             `json:"state"`
+	Failures int                `json:"failures"`
+	Attempts []ReconnectAttempt `json:"attempts"`
+}
+
+type LimitStatus struct {
+	MaxBytes    int64         `json:"max_bytes"`
+	Bytes       int64         `json:"bytes"`
+	MaxDuration time.Duration `json:"max_duration"`
+	Duration    time.Duration `json:"duration"`
+	EndSession  bool          `json:"end_session"`
+}
+
+const (
+	EventTypeSample = "sample"
+	EventTypeState  = "state"
+	EventTypeStep   = "step"
+
+	StateConnecting = "connecting"
+	StateDown       = "down"
+	StateError      = "error"
+	StateUp         = "up"
+
+	StepConnect    = "connect"
+	StepDisconnect = "disconnect"
+	StepHandshake  = "handshake"
+	StepNode       = "node"
+	StepRollback   = "rollback"
+	StepSession    = "session"
+
+	StepStateDone    = "done"
+	StepStateFailed  = "failed"
+	StepStateStarted = "started"
+)
+
+type Event struct {
+	Type         string    `json:"type"`
+	Name         string    `json:"name"`
+	Time         time.Time `json:"time"`
+	State        string    `json:"state,omitempty"`
+	Step         string    `json:"step,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Session      uint64    `json:"session,omitempty"`
+	Upload       int64     `json:"upload,omitempty"`
+	Download     int64     `json:"download,omitempty"`
+	UploadRate   float64   `json:"upload_rate,omitempty"`
+	DownloadRate float64   `json:"download_rate,omitempty"`
+}
+
+func NewStateEvent(name, state string, err error) Event {
+	e := Event{
+		Type:  EventTypeState,
+		Name:  name,
+		Time:  time.Now(),
+		State: state,
+	}
+
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	return e
+}
+
+func NewStepEvent(name, step, state string, err error) Event {
+	e := Event{
+		Type:  EventTypeStep,
+		Name:  name,
+		Time:  time.Now(),
+		State: state,
+		Step:  step,
+	}
+
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	return e
+}
+
+func NewSampleEvent(name string, upload, download int64, uploadRate, downloadRate float64) Event {
+	return Event{
+		Type:         EventTypeSample,
+		Name:         name,
+		Time:         time.Now(),
+		Upload:       upload,
+		Download:     download,
+		UploadRate:   uploadRate,
+		DownloadRate: downloadRate,
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"bufio"
 	"os"
+	"strings"
 )
 
 func ReadLineFromFile(filename string) (string, error) {
@@ -20,3 +21,19 @@ func ReadLineFromFile(filename string) (string, error) {
 
 	return string(buf), nil
 }
+
+func ReadLinesFromFile(filename string) ([]string, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+
+	return items, nil
+}
@@ -19,3 +19,19 @@ func GetFreeUDPPort() (uint16, error) {
 
 	return uint16(conn.LocalAddr().(*net.UDPAddr).Port), nil
 }
+
+func GetFreeTCPPort() (uint16, error) {
+	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+
+	l, err := net.ListenTCP("tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+
+	defer l.Close()
+
+	return uint16(l.Addr().(*net.TCPAddr).Port), nil
+}
@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	PeerCredentialsSupported = false
+)
+
+func PeerUID(_ *net.UnixConn) (uint32, error) {
+	return 0, errors.New("peer credentials are not supported on this platform")
+}
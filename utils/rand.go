@@ -3,6 +3,7 @@ package utils
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 )
 
 func RandomHexString(l int) string {
@@ -11,3 +12,12 @@ func RandomHexString(l int) string {
 
 	return base64.StdEncoding.EncodeToString(v)
 }
+
+func RandomToken(l int) (string, error) {
+	v := make([]byte, l)
+	if _, err := rand.Read(v); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(v), nil
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	clitypes "github.com/sentinel-official/cli-client/types"
@@ -27,3 +28,13 @@ func WriteResultToResponseBody(w http.ResponseWriter, code int, res interface{})
 		clitypes.NewRestResponseBody(nil, res),
 	)
 }
+
+func WriteEventToResponseBody(w http.ResponseWriter, e clitypes.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+	return err
+}
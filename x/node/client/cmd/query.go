@@ -1,11 +1,7 @@
 package cmd
 
 import (
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
 	"sync"
 	"time"
 
@@ -35,50 +31,6 @@ var (
 	}
 )
 
-func fetchNodeInfo(remote string, timeout time.Duration) (info types.NodeInfo, err error) {
-	var (
-		body   clitypes.RestResponseBody
-		client = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
-			Timeout: timeout,
-		}
-	)
-
-	path, err := url.JoinPath(remote, "status")
-	if err != nil {
-		return info, err
-	}
-
-	start := time.Now()
-
-	resp, err := client.Get(path)
-	if err != nil {
-		return info, err
-	}
-
-	info.Latency = time.Since(start)
-	defer resp.Body.Close()
-
-	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		return info, err
-	}
-
-	result, err := json.Marshal(body.Result)
-	if err != nil {
-		return info, err
-	}
-
-	if err := json.Unmarshal(result, &info); err != nil {
-		return info, err
-	}
-
-	return info, nil
-}
-
 func QueryNode() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "node [address]",
@@ -106,7 +58,7 @@ func QueryNode() *cobra.Command {
 			}
 
 			var (
-				info, _ = fetchNodeInfo(result.RemoteURL, timeout)
+				info, _ = types.FetchNodeInfo(result.RemoteURL, timeout)
 				item    = types.NewNodeFromRaw(result).WithInfo(info)
 				table   = tablewriter.NewWriter(cmd.OutOrStdout())
 			)
@@ -205,7 +157,7 @@ func QueryNodes() *cobra.Command {
 					defer wg.Done()
 
 					var (
-						info, _ = fetchNodeInfo(items[i].RemoteURL, timeout)
+						info, _ = types.FetchNodeInfo(items[i].RemoteURL, timeout)
 						item    = types.NewNodeFromRaw(&items[i]).WithInfo(info)
 					)
 
@@ -0,0 +1,138 @@
+package handshake
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/transport/http/jsonrpc"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+)
+
+const (
+	DefaultRetries  = 3
+	DefaultInterval = 2 * time.Second
+)
+
+type Request struct {
+	Key       []byte `json:"key"`
+	Signature []byte `json:"signature"`
+}
+
+type Response struct {
+	Success bool                `json:"success"`
+	Error   *clitypes.RestError `json:"error,omitempty"`
+	Result  json.RawMessage     `json:"result,omitempty"`
+}
+
+type Client struct {
+	client   http.Client
+	retries  int
+	interval time.Duration
+	infoSize int
+}
+
+func NewClient() *Client {
+	return &Client{
+		client:   clitypes.NewHTTPClient(clitypes.Timeout),
+		retries:  DefaultRetries,
+		interval: DefaultInterval,
+	}
+}
+
+func (c *Client) WithHTTPClient(v http.Client) *Client { c.client = v; return c }
+func (c *Client) WithRetries(v int) *Client            { c.retries = v; return c }
+func (c *Client) WithInterval(v time.Duration) *Client { c.interval = v; return c }
+func (c *Client) WithInfoSize(v int) *Client           { c.infoSize = v; return c }
+
+func (c *Client) Handshake(remote, from string, id uint64, key, signature []byte) (info []byte, err error) {
+	endpoint := fmt.Sprintf("%s/accounts/%s/sessions/%d", strings.Trim(remote, "/"), from, id)
+
+	buf, err := json.Marshal(
+		&Request{
+			Key:       key,
+			Signature: signature,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; ; i++ {
+		info, err = c.do(endpoint, buf)
+		if err == nil || i >= c.retries || !IsTemporary(err) {
+			return info, err
+		}
+
+		time.Sleep(time.Duration(i+1) * c.interval)
+	}
+}
+
+func (c *Client) do(endpoint string, buf []byte) ([]byte, error) {
+	resp, err := c.client.Post(endpoint, jsonrpc.ContentType, bytes.NewReader(buf))
+	if err != nil {
+		return nil, &Error{Kind: ErrNodeUnreachable, Message: err.Error()}
+	}
+
+	defer resp.Body.Close()
+
+	var body Response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, &Error{Kind: ErrNodeFailure, Status: resp.StatusCode, Message: err.Error()}
+	}
+
+	if body.Error != nil || resp.StatusCode >= http.StatusBadRequest {
+		return nil, newError(resp.StatusCode, body.Error)
+	}
+
+	var result string
+	if err := json.Unmarshal(body.Result, &result); err != nil {
+		return nil, &Error{Kind: ErrMalformedInfo, Status: resp.StatusCode, Message: "result must be a string"}
+	}
+
+	info, err := base64.StdEncoding.DecodeString(result)
+	if err != nil {
+		return nil, &Error{Kind: ErrMalformedInfo, Status: resp.StatusCode, Message: err.Error()}
+	}
+	if len(info) == 0 {
+		return nil, &Error{Kind: ErrMalformedInfo, Status: resp.StatusCode, Message: "info cannot be empty"}
+	}
+	if c.infoSize > 0 && len(info) != c.infoSize {
+		return nil, &Error{
+			Kind:    ErrMalformedInfo,
+			Status:  resp.StatusCode,
+			Message: fmt.Sprintf("info length must be %d bytes", c.infoSize),
+		}
+	}
+
+	return info, nil
+}
+
+func newError(status int, v *clitypes.RestError) *Error {
+	e := &Error{
+		Kind:   ErrNodeFailure,
+		Status: status,
+	}
+
+	if v != nil {
+		e.Code = v.Code
+		e.Message = v.Message
+	}
+
+	message := strings.ToLower(e.Message)
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden,
+		strings.Contains(message, "signature"):
+		e.Kind = ErrSignatureRejected
+	case status == http.StatusNotFound,
+		strings.Contains(message, "session") && strings.Contains(message, "does not exist"):
+		e.Kind = ErrSessionNotFound
+	}
+
+	return e
+}
@@ -0,0 +1,217 @@
+package handshake_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+	"github.com/sentinel-official/cli-client/x/node/handshake"
+)
+
+const (
+	from = "sent1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu"
+	id   = 7
+)
+
+type node struct {
+	*httptest.Server
+	requests int32
+}
+
+func newNode(t *testing.T, fn func(n int, w http.ResponseWriter, r *http.Request)) *node {
+	n := &node{}
+	n.Server = httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fn(int(atomic.AddInt32(&n.requests, 1)), w, r)
+		}),
+	)
+
+	t.Cleanup(n.Close)
+	return n
+}
+
+func (n *node) count() int {
+	return int(atomic.LoadInt32(&n.requests))
+}
+
+func write(w http.ResponseWriter, status int, err *clitypes.RestError, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(clitypes.NewRestResponseBody(err, result))
+}
+
+func newClient(size int) *handshake.Client {
+	return handshake.NewClient().
+		WithRetries(2).
+		WithInterval(time.Millisecond).
+		WithInfoSize(size)
+}
+
+func TestHandshake(t *testing.T) {
+	info := make([]byte, 58)
+	for i := range info {
+		info[i] = byte(i)
+	}
+
+	n := newNode(t, func(_ int, w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/accounts/"+from+"/sessions/7" {
+			write(w, http.StatusNotFound, clitypes.NewRestError(404, "unexpected request"), nil)
+			return
+		}
+
+		var req handshake.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			write(w, http.StatusBadRequest, clitypes.NewRestError(1, err.Error()), nil)
+			return
+		}
+		if string(req.Key) != "key" || string(req.Signature) != "signature" {
+			write(w, http.StatusBadRequest, clitypes.NewRestError(2, "invalid body"), nil)
+			return
+		}
+
+		write(w, http.StatusCreated, nil, base64.StdEncoding.EncodeToString(info))
+	})
+
+	res, err := newClient(58).Handshake(n.URL+"/", from, id, []byte("key"), []byte("signature"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(res) != string(info) {
+		t.Fatalf("info mismatch: got %x, want %x", res, info)
+	}
+}
+
+func TestHandshakeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		fn       func(n int, w http.ResponseWriter, r *http.Request)
+		kind     error
+		requests int
+	}{
+		{
+			name: "session not found is retried",
+			fn: func(_ int, w http.ResponseWriter, _ *http.Request) {
+				write(w, http.StatusNotFound, clitypes.NewRestError(7, "session does not exist"), nil)
+			},
+			kind:     handshake.ErrSessionNotFound,
+			requests: 3,
+		},
+		{
+			name: "signature rejected is not retried",
+			fn: func(_ int, w http.ResponseWriter, _ *http.Request) {
+				write(w, http.StatusBadRequest, clitypes.NewRestError(5, "failed to verify the signature"), nil)
+			},
+			kind:     handshake.ErrSignatureRejected,
+			requests: 1,
+		},
+		{
+			name: "unauthorized is a rejected signature",
+			fn: func(_ int, w http.ResponseWriter, _ *http.Request) {
+				write(w, http.StatusUnauthorized, clitypes.NewRestError(6, "account address mismatch"), nil)
+			},
+			kind:     handshake.ErrSignatureRejected,
+			requests: 1,
+		},
+		{
+			name: "result is not a string",
+			fn: func(_ int, w http.ResponseWriter, _ *http.Request) {
+				write(w, http.StatusCreated, nil, map[string]int{"info": 1})
+			},
+			kind:     handshake.ErrMalformedInfo,
+			requests: 1,
+		},
+		{
+			name: "result is not base64",
+			fn: func(_ int, w http.ResponseWriter, _ *http.Request) {
+				write(w, http.StatusCreated, nil, "!!!")
+			},
+			kind:     handshake.ErrMalformedInfo,
+			requests: 1,
+		},
+		{
+			name: "info has the wrong size",
+			size: 58,
+			fn: func(_ int, w http.ResponseWriter, _ *http.Request) {
+				write(w, http.StatusCreated, nil, base64.StdEncoding.EncodeToString([]byte{1, 2, 3}))
+			},
+			kind:     handshake.ErrMalformedInfo,
+			requests: 1,
+		},
+		{
+			name: "server error is retried",
+			fn: func(_ int, w http.ResponseWriter, _ *http.Request) {
+				write(w, http.StatusInternalServerError, clitypes.NewRestError(8, "failed to add the peer"), nil)
+			},
+			kind:     handshake.ErrNodeFailure,
+			requests: 3,
+		},
+		{
+			name: "invalid body is a node failure",
+			fn: func(_ int, w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				_, _ = w.Write([]byte("<html></html>"))
+			},
+			kind:     handshake.ErrNodeFailure,
+			requests: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNode(t, tt.fn)
+
+			_, err := newClient(tt.size).Handshake(n.URL, from, id, []byte("key"), []byte("signature"))
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("got error %v, want %v", err, tt.kind)
+			}
+			if n.count() != tt.requests {
+				t.Fatalf("got %d requests, want %d", n.count(), tt.requests)
+			}
+
+			var e *handshake.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("error %T is not a *handshake.Error", err)
+			}
+		})
+	}
+}
+
+func TestHandshakeRetrySucceeds(t *testing.T) {
+	n := newNode(t, func(n int, w http.ResponseWriter, _ *http.Request) {
+		if n < 3 {
+			write(w, http.StatusNotFound, clitypes.NewRestError(7, "session does not exist"), nil)
+			return
+		}
+
+		write(w, http.StatusCreated, nil, base64.StdEncoding.EncodeToString([]byte{1, 2, 3}))
+	})
+
+	info, err := newClient(3).Handshake(n.URL, from, id, []byte("key"), []byte("signature"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(info) != 3 || n.count() != 3 {
+		t.Fatalf("got info %x after %d requests", info, n.count())
+	}
+}
+
+func TestHandshakeNodeUnreachable(t *testing.T) {
+	n := newNode(t, func(int, http.ResponseWriter, *http.Request) {})
+	n.Close()
+
+	_, err := newClient(0).Handshake(n.URL, from, id, []byte("key"), []byte("signature"))
+	if !errors.Is(err, handshake.ErrNodeUnreachable) {
+		t.Fatalf("got error %v, want %v", err, handshake.ErrNodeUnreachable)
+	}
+	if !handshake.IsTemporary(err) {
+		t.Fatal("unreachable node must be temporary")
+	}
+}
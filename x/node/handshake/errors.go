@@ -0,0 +1,55 @@
+package handshake
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNodeUnreachable   = errors.New("node is unreachable")
+	ErrSessionNotFound   = errors.New("session does not exist")
+	ErrSignatureRejected = errors.New("signature is rejected")
+	ErrMalformedInfo     = errors.New("malformed info")
+	ErrNodeFailure       = errors.New("node failure")
+)
+
+type Error struct {
+	Kind    error
+	Status  int
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Kind.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func (e *Error) Temporary() bool {
+	switch e.Kind {
+	case ErrNodeUnreachable, ErrSessionNotFound:
+		return true
+	case ErrNodeFailure:
+		return e.Status >= http.StatusInternalServerError || e.Status == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+func IsTemporary(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Temporary()
+	}
+
+	return false
+}
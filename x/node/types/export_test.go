@@ -0,0 +1,6 @@
+package types
+
+var (
+	CompareVersions = compareVersions
+	NodeInfoWorkers = nodeInfoWorkers
+)
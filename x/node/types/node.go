@@ -1,6 +1,7 @@
 package types
 
 import (
+	"sync"
 	"time"
 
 	nodetypes "github.com/sentinel-official/hub/x/node/types"
@@ -8,6 +9,10 @@ import (
 	clitypes "github.com/sentinel-official/cli-client/types"
 )
 
+const (
+	nodeInfoWorkers = 16
+)
+
 type Node struct {
 	NodeInfo
 	Address   string         `json:"address"`
@@ -30,3 +35,34 @@ func NewNodeFromRaw(v *nodetypes.Node) Node {
 		StatusAt:  v.StatusAt,
 	}
 }
+
+func FetchNodesWithInfo(v nodetypes.Nodes, timeout time.Duration) ([]Node, []error) {
+	var (
+		wg      sync.WaitGroup
+		items   = make([]Node, len(v))
+		errs    = make([]error, len(v))
+		indexes = make(chan int)
+	)
+
+	for n := 0; n < nodeInfoWorkers && n < len(v); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				var info NodeInfo
+				info, errs[i] = FetchNodeInfo(v[i].RemoteURL, timeout)
+				items[i] = NewNodeFromRaw(&v[i]).WithInfo(info)
+			}
+		}()
+	}
+
+	for i := 0; i < len(v); i++ {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return items, errs
+}
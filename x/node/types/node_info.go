@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/json"
+	"net/url"
 	"time"
 
 	clitypes "github.com/sentinel-official/cli-client/types"
@@ -35,3 +37,40 @@ type (
 		Version                string             `json:"version"`
 	}
 )
+
+func FetchNodeInfo(remote string, timeout time.Duration) (info NodeInfo, err error) {
+	var (
+		body   clitypes.RestResponseBody
+		client = clitypes.NewHTTPClient(timeout)
+	)
+
+	path, err := url.JoinPath(remote, "status")
+	if err != nil {
+		return info, err
+	}
+
+	start := time.Now()
+
+	resp, err := client.Get(path)
+	if err != nil {
+		return info, err
+	}
+
+	info.Latency = time.Since(start)
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return info, err
+	}
+
+	result, err := json.Marshal(body.Result)
+	if err != nil {
+		return info, err
+	}
+
+	if err := json.Unmarshal(result, &info); err != nil {
+		return info, err
+	}
+
+	return info, nil
+}
@@ -0,0 +1,80 @@
+package types_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	nodetypes "github.com/sentinel-official/hub/x/node/types"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+	"github.com/sentinel-official/cli-client/x/node/types"
+)
+
+func TestFetchNodesWithInfo(t *testing.T) {
+	var (
+		active  int32
+		maximum int32
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+
+		for {
+			v := atomic.LoadInt32(&maximum)
+			if n <= v || atomic.CompareAndSwapInt32(&maximum, v, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		moniker := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/status")
+		_ = json.NewEncoder(w).Encode(clitypes.NewRestResponseBody(nil, types.NodeInfo{Moniker: moniker}))
+	}))
+	defer server.Close()
+
+	var v nodetypes.Nodes
+	for i := 0; i < 4*types.NodeInfoWorkers; i++ {
+		remoteURL := fmt.Sprintf("%s/node%d", server.URL, i)
+		if i%3 == 0 {
+			remoteURL = "http://127.0.0.1:0"
+		}
+
+		v = append(v, nodetypes.Node{Address: remoteURL, RemoteURL: remoteURL})
+	}
+
+	items, errs := types.FetchNodesWithInfo(v, time.Second)
+	if len(items) != len(v) || len(errs) != len(v) {
+		t.Fatalf("expected %d items and errors, got %d and %d", len(v), len(items), len(errs))
+	}
+
+	for i := range v {
+		if i%3 == 0 {
+			if errs[i] == nil {
+				t.Fatalf("expected an error for node %d", i)
+			}
+			continue
+		}
+
+		if errs[i] != nil {
+			t.Fatalf("unexpected error for node %d: %s", i, errs[i])
+		}
+		if expected := strings.TrimPrefix(v[i].RemoteURL, server.URL+"/"); items[i].Moniker != expected {
+			t.Fatalf("expected moniker %s for node %d, got %s", expected, i, items[i].Moniker)
+		}
+		if items[i].Address != v[i].Address {
+			t.Fatalf("expected address %s for node %d, got %s", v[i].Address, i, items[i].Address)
+		}
+	}
+
+	if n := atomic.LoadInt32(&maximum); int(n) > types.NodeInfoWorkers {
+		t.Fatalf("expected at most %d concurrent requests, got %d", types.NodeInfoWorkers, n)
+	}
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	LatencyBucket = 50 * time.Millisecond
+)
+
+type Filter struct {
+	Country  string
+	MaxPrice sdk.Coin
+	Provider string
+	Types    []uint64
+}
+
+func (f Filter) Match(n Node) bool {
+	if f.Country != "" && !strings.EqualFold(n.Location.Country, f.Country) {
+		return false
+	}
+	if f.Provider != "" && n.Provider != f.Provider {
+		return false
+	}
+	if f.MaxPrice.IsValid() && !f.MaxPrice.IsZero() {
+		price := n.Price.Raw()
+		if !price.Empty() {
+			amount := price.AmountOf(f.MaxPrice.Denom)
+			if amount.IsZero() || amount.GT(f.MaxPrice.Amount) {
+				return false
+			}
+		}
+	}
+	if len(f.Types) > 0 {
+		for _, t := range f.Types {
+			if n.Type == t {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+func compareVersions(a, b string) int {
+	var (
+		x = strings.Split(strings.SplitN(strings.TrimPrefix(a, "v"), "-", 2)[0], ".")
+		y = strings.Split(strings.SplitN(strings.TrimPrefix(b, "v"), "-", 2)[0], ".")
+	)
+
+	for i := 0; i < len(x) || i < len(y); i++ {
+		var m, n int
+		if i < len(x) {
+			m, _ = strconv.Atoi(x[i])
+		}
+		if i < len(y) {
+			n, _ = strconv.Atoi(y[i])
+		}
+
+		if m != n {
+			if m < n {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func RankNodes(items []Node, denom string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		var (
+			x = items[i].Latency / LatencyBucket
+			y = items[j].Latency / LatencyBucket
+		)
+
+		if x != y {
+			return x < y
+		}
+		if items[i].Peers != items[j].Peers {
+			return items[i].Peers < items[j].Peers
+		}
+		if v := compareVersions(items[i].Version, items[j].Version); v != 0 {
+			return v > 0
+		}
+		if denom == "" {
+			return false
+		}
+
+		return items[i].Price.Raw().AmountOf(denom).LT(items[j].Price.Raw().AmountOf(denom))
+	})
+}
@@ -0,0 +1,143 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	clitypes "github.com/sentinel-official/cli-client/types"
+	"github.com/sentinel-official/cli-client/x/node/types"
+)
+
+func newNode(address string, price ...sdk.Coin) types.Node {
+	return types.Node{
+		Address:  address,
+		Provider: "sentprov1",
+		Price:    clitypes.NewCoinsFromRaw(sdk.NewCoins(price...)),
+		NodeInfo: types.NodeInfo{
+			Location: types.Location{Country: "Germany"},
+			Type:     clitypes.ServiceTypeWireGuard,
+		},
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	var (
+		node   = newNode("a", sdk.NewInt64Coin("udvpn", 100))
+		noDVPN = newNode("b", sdk.NewInt64Coin("uatom", 100))
+		free   = newNode("c")
+	)
+
+	tests := []struct {
+		name   string
+		filter types.Filter
+		node   types.Node
+		match  bool
+	}{
+		{"empty", types.Filter{}, node, true},
+		{"country", types.Filter{Country: "germany"}, node, true},
+		{"other country", types.Filter{Country: "France"}, node, false},
+		{"provider", types.Filter{Provider: "sentprov1"}, node, true},
+		{"other provider", types.Filter{Provider: "sentprov2"}, node, false},
+		{"price below", types.Filter{MaxPrice: sdk.NewInt64Coin("udvpn", 200)}, node, true},
+		{"price equal", types.Filter{MaxPrice: sdk.NewInt64Coin("udvpn", 100)}, node, true},
+		{"price above", types.Filter{MaxPrice: sdk.NewInt64Coin("udvpn", 50)}, node, false},
+		{"price in other denom", types.Filter{MaxPrice: sdk.NewInt64Coin("udvpn", 200)}, noDVPN, false},
+		{"price empty", types.Filter{MaxPrice: sdk.NewInt64Coin("udvpn", 50)}, free, true},
+		{"price zero", types.Filter{MaxPrice: sdk.NewInt64Coin("udvpn", 0)}, node, true},
+		{"type", types.Filter{Types: []uint64{clitypes.ServiceTypeV2Ray, clitypes.ServiceTypeWireGuard}}, node, true},
+		{"other type", types.Filter{Types: []uint64{clitypes.ServiceTypeV2Ray}}, node, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v := tt.filter.Match(tt.node); v != tt.match {
+				t.Fatalf("expected %t, got %t", tt.match, v)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.7.1", "0.7.1", 0},
+		{"v0.7.1", "0.7.1", 0},
+		{"0.7.1", "0.7.0", 1},
+		{"0.7.0", "0.7.1", -1},
+		{"0.10.0", "0.9.9", 1},
+		{"1.0", "1.0.0", 0},
+		{"1.0.1", "1.0", 1},
+		{"0.7.1-rc1", "0.7.1", 0},
+		{"", "0.0.1", -1},
+		{"abc", "0.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if v := types.CompareVersions(tt.a, tt.b); v != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, v)
+			}
+		})
+	}
+}
+
+func TestRankNodes(t *testing.T) {
+	node := func(address string, latency time.Duration, peers int, version string, price int64) types.Node {
+		v := newNode(address, sdk.NewInt64Coin("udvpn", price))
+		v.Latency, v.Peers, v.Version = latency, peers, version
+		return v
+	}
+
+	tests := []struct {
+		name  string
+		denom string
+		items []types.Node
+		want  []string
+	}{
+		{
+			"latency bucket",
+			"udvpn",
+			[]types.Node{node("a", 120*time.Millisecond, 0, "", 1), node("b", 30*time.Millisecond, 9, "", 9)},
+			[]string{"b", "a"},
+		},
+		{
+			"peers within bucket",
+			"udvpn",
+			[]types.Node{node("a", 10*time.Millisecond, 5, "", 1), node("b", 40*time.Millisecond, 2, "", 9)},
+			[]string{"b", "a"},
+		},
+		{
+			"newer version",
+			"udvpn",
+			[]types.Node{node("a", 0, 1, "0.7.0", 1), node("b", 0, 1, "0.10.0", 9)},
+			[]string{"b", "a"},
+		},
+		{
+			"lower price",
+			"udvpn",
+			[]types.Node{node("a", 0, 1, "0.7.0", 9), node("b", 0, 1, "0.7.0", 1)},
+			[]string{"b", "a"},
+		},
+		{
+			"stable without denom",
+			"",
+			[]types.Node{node("a", 0, 1, "0.7.0", 9), node("b", 0, 1, "0.7.0", 1)},
+			[]string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			types.RankNodes(tt.items, tt.denom)
+			for i, item := range tt.items {
+				if item.Address != tt.want[i] {
+					t.Fatalf("expected order %v, got %s at %d", tt.want, item.Address, i)
+				}
+			}
+		})
+	}
+}